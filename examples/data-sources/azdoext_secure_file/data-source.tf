@@ -0,0 +1,8 @@
+data "azuredevops_project" "proj" {
+  name = "My Project"
+}
+
+data "azdoext_secure_file" "file" {
+  project_id = data.azuredevops_project.proj.id
+  name       = "hello_world.txt"
+}
@@ -0,0 +1,11 @@
+data "azuredevops_project" "proj" {
+  name = "My Project"
+}
+
+data "azdoext_secure_files" "certificates" {
+  project_id = data.azuredevops_project.proj.id
+  name_regex = "\\.(p12|pfx)$"
+  properties = {
+    team = "mobile"
+  }
+}
@@ -0,0 +1,8 @@
+data "azuredevops_project" "proj" {
+  name = "My Project"
+}
+
+ephemeral "azdoext_secure_file_content" "file" {
+  project_id = data.azuredevops_project.proj.id
+  name       = "signing.p12"
+}
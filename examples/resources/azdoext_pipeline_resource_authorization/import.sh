@@ -0,0 +1,4 @@
+# Authorizations can be imported using the project ID, resource type, resource ID and, for a single pipeline, the
+# pipeline ID, separated by slashes
+terraform import azdoext_pipeline_resource_authorization.all "00000000-0000-0000-0000-000000000000/variablegroup/7"
+terraform import azdoext_pipeline_resource_authorization.pipeline "00000000-0000-0000-0000-000000000000/securefile/00000000-0000-0000-0000-000000000001/42"
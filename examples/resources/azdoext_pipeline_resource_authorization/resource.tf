@@ -0,0 +1,24 @@
+data "azuredevops_project" "proj" {
+  name = "My Project"
+}
+
+resource "azdoext_secure_file" "file" {
+  project_id = data.azuredevops_project.proj.id
+  name       = "signing.p12"
+  source     = "signing.p12"
+}
+
+# Authorize a single pipeline to use the secure file
+resource "azdoext_pipeline_resource_authorization" "pipeline" {
+  project_id    = data.azuredevops_project.proj.id
+  resource_type = "securefile"
+  resource_id   = azdoext_secure_file.file.id
+  pipeline_id   = 42
+}
+
+# Authorize all pipelines to use a variable group
+resource "azdoext_pipeline_resource_authorization" "all" {
+  project_id    = data.azuredevops_project.proj.id
+  resource_type = "variablegroup"
+  resource_id   = "7"
+}
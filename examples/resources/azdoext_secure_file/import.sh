@@ -0,0 +1,3 @@
+# Secure files can be imported using the project ID or name and the secure file ID or name, separated by a slash
+terraform import azdoext_secure_file.file "My Project/00000000-0000-0000-0000-000000000000"
+terraform import azdoext_secure_file.file "My Project/hello_world.txt"
@@ -0,0 +1,5 @@
+# Role assignments on a secure file can be imported using the project ID, secure file ID and identity ID, separated by slashes
+terraform import azdoext_secure_file_role_assignment.file "00000000-0000-0000-0000-000000000000/00000000-0000-0000-0000-000000000001/00000000-0000-0000-0000-000000000002"
+
+# Role assignments on the secure file library can be imported using the project ID and identity ID, separated by a slash
+terraform import azdoext_secure_file_role_assignment.library "00000000-0000-0000-0000-000000000000/00000000-0000-0000-0000-000000000002"
@@ -0,0 +1,29 @@
+data "azuredevops_project" "proj" {
+  name = "My Project"
+}
+
+data "azuredevops_group" "release_managers" {
+  project_id = data.azuredevops_project.proj.id
+  name       = "Release Managers"
+}
+
+resource "azdoext_secure_file" "file" {
+  project_id = data.azuredevops_project.proj.id
+  name       = "signing.p12"
+  source     = "signing.p12"
+}
+
+# Assign a role on a single secure file
+resource "azdoext_secure_file_role_assignment" "file" {
+  project_id     = data.azuredevops_project.proj.id
+  secure_file_id = azdoext_secure_file.file.id
+  identity_id    = data.azuredevops_group.release_managers.origin_id
+  role           = "Administrator"
+}
+
+# Assign a role on the secure file library of the project
+resource "azdoext_secure_file_role_assignment" "library" {
+  project_id  = data.azuredevops_project.proj.id
+  identity_id = data.azuredevops_group.release_managers.origin_id
+  role        = "Reader"
+}
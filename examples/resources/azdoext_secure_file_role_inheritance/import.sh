@@ -0,0 +1,5 @@
+# Inheritance on a secure file can be imported using the project ID and secure file ID, separated by a slash
+terraform import azdoext_secure_file_role_inheritance.file "00000000-0000-0000-0000-000000000000/00000000-0000-0000-0000-000000000001"
+
+# Inheritance on the secure file library can be imported using the project ID
+terraform import azdoext_secure_file_role_inheritance.library "00000000-0000-0000-0000-000000000000"
@@ -0,0 +1,16 @@
+data "azuredevops_project" "proj" {
+  name = "My Project"
+}
+
+resource "azdoext_secure_file" "file" {
+  project_id = data.azuredevops_project.proj.id
+  name       = "signing.p12"
+  source     = "signing.p12"
+}
+
+# Stop a single secure file inheriting role assignments from the library
+resource "azdoext_secure_file_role_inheritance" "file" {
+  project_id          = data.azuredevops_project.proj.id
+  secure_file_id      = azdoext_secure_file.file.id
+  inherit_permissions = false
+}
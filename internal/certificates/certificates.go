@@ -0,0 +1,180 @@
+package certificates
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"howett.net/plist"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	TypeCertificate         = "certificate"
+	TypeProvisioningProfile = "provisioning_profile"
+)
+
+// Certificate describes an X.509 certificate or an Apple provisioning profile contained in a file.
+type Certificate struct {
+	Type    string
+	Subject string
+	Issuer  string
+	// Thumbprint is the SHA-1 thumbprint of a certificate, or the UUID of a provisioning profile.
+	Thumbprint string
+	NotBefore  time.Time
+	NotAfter   time.Time
+	// TeamId is the Apple developer team ID of a provisioning profile and the certificates embedded within it.
+	TeamId string
+}
+
+type parser func(data []byte, password string) ([]Certificate, error)
+
+var parsers = map[string]parser{
+	".p12":             parsePkcs12,
+	".pfx":             parsePkcs12,
+	".pem":             parsePemOrDer,
+	".crt":             parsePemOrDer,
+	".cer":             parsePemOrDer,
+	".mobileprovision": parseProvisioningProfile,
+}
+
+// IsRecognised reports whether files with the given name are in a format Parse understands.
+func IsRecognised(name string) bool {
+	_, ok := parsers[strings.ToLower(path.Ext(name))]
+	return ok
+}
+
+// Parse returns the certificates contained in a file, choosing the format to parse it as from the file's extension.
+// Files in an unrecognised format contain no certificates.
+func Parse(name string, data []byte, password string) ([]Certificate, error) {
+	parse, ok := parsers[strings.ToLower(path.Ext(name))]
+	if !ok {
+		return nil, nil
+	}
+	return parse(data, password)
+}
+
+func parsePkcs12(data []byte, password string) ([]Certificate, error) {
+	_, certificate, caCertificates, err := pkcs12.DecodeChain(data, password)
+	if err == nil {
+		return fromX509(append([]*x509.Certificate{certificate}, caCertificates...), ""), nil
+	}
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, err
+	}
+
+	// Files without a private key, such as those exported by Java's keytool, are trust stores
+	trustedCertificates, trustStoreErr := pkcs12.DecodeTrustStore(data, password)
+	if trustStoreErr != nil {
+		return nil, err
+	}
+	return fromX509(trustedCertificates, ""), nil
+}
+
+func parsePemOrDer(data []byte, _ string) ([]Certificate, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		certificates, err := x509.ParseCertificates(data)
+		if err != nil {
+			return nil, err
+		}
+		return fromX509(certificates, ""), nil
+	}
+
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	return fromX509(certificates, ""), nil
+}
+
+type provisioningProfile struct {
+	Name                  string    `plist:"Name"`
+	UUID                  string    `plist:"UUID"`
+	TeamName              string    `plist:"TeamName"`
+	TeamIdentifier        []string  `plist:"TeamIdentifier"`
+	CreationDate          time.Time `plist:"CreationDate"`
+	ExpirationDate        time.Time `plist:"ExpirationDate"`
+	DeveloperCertificates [][]byte  `plist:"DeveloperCertificates"`
+}
+
+// parseProvisioningProfile reads the property list embedded in a provisioning profile. The profile is a signed CMS
+// message, but the property list is stored within it unencrypted, so it is located directly rather than by parsing the
+// message.
+func parseProvisioningProfile(data []byte, _ string) ([]Certificate, error) {
+	start := bytes.Index(data, []byte("<?xml"))
+	end := bytes.Index(data, []byte("</plist>"))
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no property list found in provisioning profile")
+	}
+
+	var profile provisioningProfile
+	if _, err := plist.Unmarshal(data[start:end+len("</plist>")], &profile); err != nil {
+		return nil, err
+	}
+
+	teamId := ""
+	if len(profile.TeamIdentifier) > 0 {
+		teamId = profile.TeamIdentifier[0]
+	}
+
+	certificates := []Certificate{
+		{
+			Type:       TypeProvisioningProfile,
+			Subject:    profile.Name,
+			Issuer:     profile.TeamName,
+			Thumbprint: profile.UUID,
+			NotBefore:  profile.CreationDate,
+			NotAfter:   profile.ExpirationDate,
+			TeamId:     teamId,
+		},
+	}
+
+	for _, developerCertificate := range profile.DeveloperCertificates {
+		certificate, err := x509.ParseCertificate(developerCertificate)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, fromX509([]*x509.Certificate{certificate}, teamId)...)
+	}
+
+	return certificates, nil
+}
+
+func fromX509(x509Certificates []*x509.Certificate, teamId string) []Certificate {
+	certificates := make([]Certificate, 0, len(x509Certificates))
+	for _, certificate := range x509Certificates {
+		thumbprint := sha1.Sum(certificate.Raw)
+		certificates = append(
+			certificates, Certificate{
+				Type:       TypeCertificate,
+				Subject:    certificate.Subject.String(),
+				Issuer:     certificate.Issuer.String(),
+				Thumbprint: strings.ToUpper(hex.EncodeToString(thumbprint[:])),
+				NotBefore:  certificate.NotBefore.UTC(),
+				NotAfter:   certificate.NotAfter.UTC(),
+				TeamId:     teamId,
+			},
+		)
+	}
+	return certificates
+}
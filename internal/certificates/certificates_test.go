@@ -0,0 +1,177 @@
+package certificates
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"howett.net/plist"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	testNotBefore = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNotAfter  = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newTestCertificate(t *testing.T, commonName string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    testNotBefore,
+		NotAfter:     testNotAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate, key
+}
+
+func thumbprint(certificate *x509.Certificate) string {
+	sum := sha1.Sum(certificate.Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func expectedCertificate(certificate *x509.Certificate, teamId string) Certificate {
+	return Certificate{
+		Type:       TypeCertificate,
+		Subject:    certificate.Subject.String(),
+		Issuer:     certificate.Issuer.String(),
+		Thumbprint: thumbprint(certificate),
+		NotBefore:  testNotBefore,
+		NotAfter:   testNotAfter,
+		TeamId:     teamId,
+	}
+}
+
+func TestIsRecognised(t *testing.T) {
+	require.True(t, IsRecognised("signing.p12"))
+	require.True(t, IsRecognised("SIGNING.PFX"))
+	require.True(t, IsRecognised("ca.pem"))
+	require.True(t, IsRecognised("ca.crt"))
+	require.True(t, IsRecognised("ca.cer"))
+	require.True(t, IsRecognised("app.mobileprovision"))
+	require.False(t, IsRecognised("hello_world.txt"))
+	require.False(t, IsRecognised("p12"))
+}
+
+func TestParsePkcs12(t *testing.T) {
+	certificate, key := newTestCertificate(t, "leaf")
+	caCertificate, _ := newTestCertificate(t, "ca")
+
+	test := func(encoder *pkcs12.Encoder) func(*testing.T) {
+		return func(t *testing.T) {
+			data, err := encoder.Encode(key, certificate, []*x509.Certificate{caCertificate}, "secret")
+			require.NoError(t, err)
+
+			certificates, err := Parse("signing.p12", data, "secret")
+			require.NoError(t, err)
+			require.Equal(
+				t, []Certificate{expectedCertificate(certificate, ""), expectedCertificate(caCertificate, "")},
+				certificates,
+			)
+
+			_, err = Parse("signing.pfx", data, "wrong")
+			require.ErrorIs(t, err, pkcs12.ErrIncorrectPassword)
+		}
+	}
+
+	t.Run("legacy", test(pkcs12.Legacy))
+	t.Run("modern", test(pkcs12.Modern))
+}
+
+func TestParsePkcs12TrustStore(t *testing.T) {
+	certificate, _ := newTestCertificate(t, "ca")
+
+	data, err := pkcs12.Modern.EncodeTrustStore([]*x509.Certificate{certificate}, "secret")
+	require.NoError(t, err)
+
+	certificates, err := Parse("truststore.p12", data, "secret")
+	require.NoError(t, err)
+	require.Equal(t, []Certificate{expectedCertificate(certificate, "")}, certificates)
+}
+
+func TestParsePemOrDer(t *testing.T) {
+	certificate, _ := newTestCertificate(t, "leaf")
+	caCertificate, _ := newTestCertificate(t, "ca")
+
+	pemData := append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCertificate.Raw})...,
+	)
+	pemData = append(pemData, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("ignored")})...)
+
+	certificates, err := Parse("chain.pem", pemData, "")
+	require.NoError(t, err)
+	require.Equal(
+		t, []Certificate{expectedCertificate(certificate, ""), expectedCertificate(caCertificate, "")}, certificates,
+	)
+
+	certificates, err = Parse("leaf.cer", certificate.Raw, "")
+	require.NoError(t, err)
+	require.Equal(t, []Certificate{expectedCertificate(certificate, "")}, certificates)
+
+	_, err = Parse("leaf.crt", []byte("not a certificate"), "")
+	require.Error(t, err)
+}
+
+func TestParseProvisioningProfile(t *testing.T) {
+	certificate, _ := newTestCertificate(t, "iPhone Distribution: Example")
+
+	profile, err := plist.Marshal(
+		provisioningProfile{
+			Name:                  "Example App Store",
+			UUID:                  "00000000-0000-0000-0000-000000000001",
+			TeamName:              "Example Ltd",
+			TeamIdentifier:        []string{"ABCDE12345"},
+			CreationDate:          testNotBefore,
+			ExpirationDate:        testNotAfter,
+			DeveloperCertificates: [][]byte{certificate.Raw},
+		}, plist.XMLFormat,
+	)
+	require.NoError(t, err)
+
+	// Surround the property list with bytes standing in for the CMS signature
+	data := append(append([]byte{0x30, 0x80, 0x06, 0x09}, profile...), 0xa0, 0x82)
+
+	certificates, err := Parse("app.mobileprovision", data, "")
+	require.NoError(t, err)
+	require.Equal(
+		t, []Certificate{
+			{
+				Type:       TypeProvisioningProfile,
+				Subject:    "Example App Store",
+				Issuer:     "Example Ltd",
+				Thumbprint: "00000000-0000-0000-0000-000000000001",
+				NotBefore:  testNotBefore,
+				NotAfter:   testNotAfter,
+				TeamId:     "ABCDE12345",
+			},
+			expectedCertificate(certificate, "ABCDE12345"),
+		}, certificates,
+	)
+
+	_, err = Parse("app.mobileprovision", []byte("not a profile"), "")
+	require.Error(t, err)
+}
+
+func TestParseUnrecognised(t *testing.T) {
+	certificates, err := Parse("hello_world.txt", []byte("Hello World"), "")
+	require.NoError(t, err)
+	require.Empty(t, certificates)
+}
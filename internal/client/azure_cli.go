@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAzureCLICommand is the Azure CLI executable, looked up on the PATH.
+	DefaultAzureCLICommand = "az"
+
+	// azureCLITimeout bounds each invocation of the Azure CLI, which may otherwise hang waiting for input.
+	azureCLITimeout = 30 * time.Second
+
+	// azureCLIExpiresOnLayout is the layout of the expiresOn field of the Azure CLI, which is in local time.
+	azureCLIExpiresOnLayout = "2006-01-02 15:04:05.999999"
+)
+
+// AzureCLICredential acquires access tokens for the account the Azure CLI is logged in with.
+type AzureCLICredential struct {
+	// Command is the path of the Azure CLI executable.
+	Command string
+	// TenantId selects the tenant the access token is issued by, or the tenant of the current subscription when empty.
+	TenantId string
+}
+
+var _ TokenCredential = &AzureCLICredential{}
+
+func NewAzureCLICredential(command string, tenantId string) *AzureCLICredential {
+	if command == "" {
+		command = DefaultAzureCLICommand
+	}
+	return &AzureCLICredential{
+		Command:  command,
+		TenantId: tenantId,
+	}
+}
+
+// azureCLITokenResponse is the output of az account get-access-token. Versions of the Azure CLI before 2.54.0 only
+// output expiresOn.
+type azureCLITokenResponse struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresOn   string `json:"expiresOn"`
+	ExpiresOnTs int64  `json:"expires_on"`
+}
+
+func (c *AzureCLICredential) Token(ctx context.Context) (AccessToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, azureCLITimeout)
+	defer cancel()
+
+	args := []string{"account", "get-access-token", "--resource", AzureDevOpsResourceId, "--output", "json"}
+	if c.TenantId != "" {
+		args = append(args, "--tenant", c.TenantId)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return AccessToken{}, fmt.Errorf(
+				"error requesting access token from the Azure CLI: %s", strings.TrimSpace(stderr.String()),
+			)
+		}
+		return AccessToken{}, fmt.Errorf("error running the Azure CLI: %v", err)
+	}
+
+	var token azureCLITokenResponse
+	if err := json.Unmarshal(stdout.Bytes(), &token); err != nil {
+		return AccessToken{}, fmt.Errorf("unexpected output from the Azure CLI: %v", err)
+	}
+	if token.AccessToken == "" {
+		return AccessToken{}, fmt.Errorf("the Azure CLI did not return an access token")
+	}
+
+	accessToken := AccessToken{Token: token.AccessToken}
+	if token.ExpiresOnTs != 0 {
+		accessToken.ExpiresOn = time.Unix(token.ExpiresOnTs, 0)
+	} else {
+		expiresOn, err := time.ParseInLocation(azureCLIExpiresOnLayout, token.ExpiresOn, time.Local)
+		if err != nil {
+			return AccessToken{}, fmt.Errorf("error parsing access token expiry from the Azure CLI: %v", err)
+		}
+		accessToken.ExpiresOn = expiresOn
+	}
+
+	return accessToken, nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAzureCLI writes a stub of the Azure CLI which checks it is asked for an access token for Azure DevOps and
+// then runs the given script.
+func newTestAzureCLI(t *testing.T, tenantId string, script string) string {
+	expectedArgs := "account get-access-token --resource " + AzureDevOpsResourceId + " --output json"
+	if tenantId != "" {
+		expectedArgs += " --tenant " + tenantId
+	}
+
+	path := filepath.Join(t.TempDir(), "az")
+	stub := "#!/bin/sh\n" +
+		"if [ \"$*\" != \"" + expectedArgs + "\" ]; then echo \"unexpected arguments: $*\" >&2; exit 2; fi\n" +
+		script + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(stub), 0700))
+	return path
+}
+
+func TestAzureCLICredential(t *testing.T) {
+	expiresOn := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	test := func(tenantId string, script string, expectedErr string) func(*testing.T) {
+		return func(t *testing.T) {
+			credential := NewAzureCLICredential(newTestAzureCLI(t, tenantId, script), tenantId)
+			token, err := credential.Token(context.Background())
+			if expectedErr != "" {
+				require.ErrorContains(t, err, expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "some-token", token.Token)
+			require.WithinDuration(t, expiresOn, token.ExpiresOn, time.Second)
+		}
+	}
+
+	t.Run(
+		"expires_on", test(
+			"", "echo '{\"accessToken\": \"some-token\", \"expiresOn\": \"2000-01-01 00:00:00.000000\", "+
+				"\"expires_on\": "+strconv.FormatInt(expiresOn.Unix(), 10)+"}'", "",
+		),
+	)
+	t.Run(
+		"legacy_expires_on", test(
+			"", "echo '{\"accessToken\": \"some-token\", \"expiresOn\": \""+
+				expiresOn.Format(azureCLIExpiresOnLayout)+"\"}'", "",
+		),
+	)
+	t.Run(
+		"tenant", test(
+			"00000000-0000-0000-0000-000000000002", "echo '{\"accessToken\": \"some-token\", \"expires_on\": "+
+				strconv.FormatInt(expiresOn.Unix(), 10)+"}'", "",
+		),
+	)
+	t.Run(
+		"not_logged_in", test(
+			"", "echo \"ERROR: Please run 'az login' to setup account.\" >&2; exit 1",
+			"ERROR: Please run 'az login' to setup account.",
+		),
+	)
+	t.Run("invalid_output", test("", "echo 'not json'", "unexpected output from the Azure CLI"))
+	t.Run("missing", func(t *testing.T) {
+		credential := NewAzureCLICredential(filepath.Join(t.TempDir(), "az"), "")
+		_, err := credential.Token(context.Background())
+		require.ErrorContains(t, err, "error running the Azure CLI")
+	})
+}
@@ -3,40 +3,50 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/pipelinepermissions"
 
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
 	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
 )
 
 type Options struct {
 	OrganisationUrl     string
 	PersonalAccessToken string
-	ProviderVersion     string
-	TerraformVersion    string
+	// Credential acquires the access token used to authenticate when no personal access token is given.
+	Credential       TokenCredential
+	ProviderVersion  string
+	TerraformVersion string
 }
 
 type Clients struct {
-	TaskAgentClient taskagent.Client
-	BuildClient     build.Client
+	TaskAgentClient           taskagent.Client
+	BuildClient               build.Client
+	CoreClient                core.Client
+	PipelinePermissionsClient pipelinepermissions.Client
+	SecurityRolesClient       securityroles.Client
 }
 
 func (o *Options) Clients(ctx context.Context) (*Clients, error) {
 	if strings.EqualFold(o.OrganisationUrl, "") {
 		return nil, fmt.Errorf("url of the Azure DevOps is required")
 	}
-	if strings.EqualFold(o.PersonalAccessToken, "") {
-		return nil, fmt.Errorf("personal access token is required")
-	}
 
-	connection := azuredevops.NewPatConnection(o.OrganisationUrl, o.PersonalAccessToken)
+	connection, transport, err := o.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
 	o.setUserAgent(ctx, connection)
 
-	taskAgentClient, err := taskagent.NewClient(ctx, connection)
+	taskAgentClient, err := taskagent.NewClientWithTransport(ctx, connection, transport)
 	if err != nil {
 		return nil, err
 	}
@@ -46,12 +56,58 @@ func (o *Options) Clients(ctx context.Context) (*Clients, error) {
 		return nil, err
 	}
 
+	coreClient, err := core.NewClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	pipelinePermissionsClient, err := pipelinepermissions.NewClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	securityRolesClient := securityroles.NewClient(ctx, connection)
+
+	if transport != nil {
+		withTransport := azuredevops.WithHTTPClient(&http.Client{Transport: transport})
+		withTransport(&buildClient.(*build.ClientImpl).Client)
+		withTransport(&coreClient.(*core.ClientImpl).Client)
+		withTransport(&pipelinePermissionsClient.(*pipelinepermissions.ClientImpl).Client)
+		withTransport(&securityRolesClient.(*securityroles.ClientImpl).Client)
+	}
+
 	return &Clients{
-		TaskAgentClient: taskAgentClient,
-		BuildClient:     buildClient,
+		TaskAgentClient:           taskAgentClient,
+		BuildClient:               buildClient,
+		CoreClient:                coreClient,
+		PipelinePermissionsClient: pipelinePermissionsClient,
+		SecurityRolesClient:       securityRolesClient,
 	}, nil
 }
 
+// connection creates a connection authenticated with the personal access token, or otherwise with a bearer token
+// acquired from the credential. Bearer tokens expire, so a transport which authenticates each request with a current
+// token is returned alongside the connection for the clients to use.
+func (o *Options) connection(ctx context.Context) (*azuredevops.Connection, http.RoundTripper, error) {
+	if !strings.EqualFold(o.PersonalAccessToken, "") {
+		return azuredevops.NewPatConnection(o.OrganisationUrl, o.PersonalAccessToken), nil, nil
+	}
+	if o.Credential == nil {
+		return nil, nil, fmt.Errorf("personal access token or credential is required")
+	}
+
+	credential := &cachedCredential{credential: o.Credential, now: time.Now}
+	token, err := credential.Token(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error acquiring access token: %v", err)
+	}
+
+	// The connection's own clients, which look up resource areas, are only used while the token is still current
+	connection := azuredevops.NewAnonymousConnection(o.OrganisationUrl)
+	connection.AuthorizationString = "Bearer " + token.Token
+	return connection, &bearerTransport{credential: credential, transport: http.DefaultTransport}, nil
+}
+
 func (o *Options) setUserAgent(ctx context.Context, connection *azuredevops.Connection) {
 	parts := []string{
 		connection.UserAgent,
@@ -70,3 +126,21 @@ func (o *Options) setUserAgent(ctx context.Context, connection *azuredevops.Conn
 		},
 	)
 }
+
+// bearerTransport authenticates each request with a current access token, so that requests made after the token the
+// clients were created with expires, such as during long applies, still succeed.
+type bearerTransport struct {
+	credential TokenCredential
+	transport  http.RoundTripper
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.credential.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing access token: %v", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+	"github.com/stretchr/testify/require"
+)
+
+var resourceAreasLocationId = uuid.MustParse("e81700f7-3be2-46de-8624-2eb35882fcaa")
+
+// newTestOrganisation stands in for an Azure DevOps organisation, recording the Authorization header of each request.
+func newTestOrganisation(t *testing.T, authorizations *[]string) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				*authorizations = append(*authorizations, r.Header.Get("Authorization"))
+
+				w.Header().Set("Content-Type", azuredevops.MediaTypeApplicationJson)
+				switch {
+				case r.Method == http.MethodOptions && r.URL.Path == "/_apis":
+					area, resourceName, routeTemplate := "Location", "ResourceAreas", "_apis/{resource}/{areaId}"
+					minVersion, maxVersion, releasedVersion, resourceVersion := "3.2", "7.1", "0.0", 1
+					_ = json.NewEncoder(w).Encode(
+						map[string]interface{}{
+							"count": 1,
+							"value": []azuredevops.ApiResourceLocation{
+								{
+									Id:              &resourceAreasLocationId,
+									Area:            &area,
+									ResourceName:    &resourceName,
+									RouteTemplate:   &routeTemplate,
+									ResourceVersion: &resourceVersion,
+									MinVersion:      &minVersion,
+									MaxVersion:      &maxVersion,
+									ReleasedVersion: &releasedVersion,
+								},
+							},
+						},
+					)
+				case r.Method == http.MethodGet && r.URL.Path == "/_apis/ResourceAreas":
+					// Respond as an on-prem server would, so everything is served from the test server
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"count": 0, "value": []interface{}{}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOptionsClients(t *testing.T) {
+	test := func(
+		options func(organisationUrl string, authorityHost string) Options, expectedAuthorization string,
+		expectedErr string,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			var authorizations []string
+			organisation := newTestOrganisation(t, &authorizations)
+			tokenEndpoint := newTestTokenEndpoint(
+				t, "some-token", func(form url.Values) string {
+					if form.Get("client_secret") != "secret" {
+						return "invalid_client"
+					}
+					return ""
+				},
+			)
+
+			o := options(organisation.URL, tokenEndpoint.URL)
+			clients, err := o.Clients(context.Background())
+			if expectedErr != "" {
+				require.ErrorContains(t, err, expectedErr)
+				require.Empty(t, authorizations)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, clients.TaskAgentClient)
+			require.NotEmpty(t, authorizations)
+			for _, authorization := range authorizations {
+				require.Equal(t, expectedAuthorization, authorization)
+			}
+		}
+	}
+
+	t.Run(
+		"personal_access_token", test(
+			func(organisationUrl string, authorityHost string) Options {
+				return Options{
+					OrganisationUrl:     organisationUrl,
+					PersonalAccessToken: "some-pat",
+					Credential:          NewClientSecretCredential(authorityHost, testTenantId, testClientId, "secret"),
+				}
+			},
+			azuredevops.CreateBasicAuthHeaderValue("", "some-pat"), "",
+		),
+	)
+	t.Run(
+		"service_principal", test(
+			func(organisationUrl string, authorityHost string) Options {
+				return Options{
+					OrganisationUrl: organisationUrl,
+					Credential:      NewClientSecretCredential(authorityHost, testTenantId, testClientId, "secret"),
+				}
+			},
+			"Bearer some-token", "",
+		),
+	)
+	t.Run(
+		"service_principal_invalid", test(
+			func(organisationUrl string, authorityHost string) Options {
+				return Options{
+					OrganisationUrl: organisationUrl,
+					Credential:      NewClientSecretCredential(authorityHost, testTenantId, testClientId, "wrong"),
+				}
+			},
+			"", "error acquiring access token",
+		),
+	)
+	t.Run(
+		"no_credentials", test(
+			func(organisationUrl string, _ string) Options {
+				return Options{OrganisationUrl: organisationUrl}
+			},
+			"", "personal access token or credential is required",
+		),
+	)
+}
+
+// sequentialCredential issues a new access token each time one is requested, each expiring after the given duration.
+type sequentialCredential struct {
+	tokens    []string
+	expiresIn time.Duration
+	now       func() time.Time
+}
+
+func (c *sequentialCredential) Token(context.Context) (AccessToken, error) {
+	if len(c.tokens) == 0 {
+		return AccessToken{}, fmt.Errorf("no more tokens")
+	}
+	token := AccessToken{Token: c.tokens[0], ExpiresOn: c.now().Add(c.expiresIn)}
+	c.tokens = c.tokens[1:]
+	return token, nil
+}
+
+func TestCachedCredential(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	credential := &cachedCredential{
+		credential: &sequentialCredential{tokens: []string{"token-1", "token-2"}, expiresIn: time.Hour, now: clock},
+		now:        clock,
+	}
+
+	expectToken := func(expected string) {
+		token, err := credential.Token(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, token.Token)
+	}
+
+	expectToken("token-1")
+	now = now.Add(time.Hour - tokenRefreshMargin - time.Second)
+	expectToken("token-1")
+	now = now.Add(time.Second)
+	expectToken("token-2")
+}
+
+func TestBearerTransport(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				authorizations = append(authorizations, r.Header.Get("Authorization"))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	// Tokens which expire within the refresh margin are refreshed before every request
+	httpClient := &http.Client{
+		Transport: &bearerTransport{
+			credential: &cachedCredential{
+				credential: &sequentialCredential{
+					tokens: []string{"token-1", "token-2"}, expiresIn: time.Minute, now: time.Now,
+				},
+				now: time.Now,
+			},
+			transport: http.DefaultTransport,
+		},
+	}
+
+	for range 2 {
+		resp, err := httpClient.Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	require.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authorizations)
+
+	_, err := httpClient.Get(server.URL)
+	require.ErrorContains(t, err, "error refreshing access token: no more tokens")
+}
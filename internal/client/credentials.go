@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AzureDevOpsResourceId is the application ID of Azure DevOps in Microsoft Entra ID, which access tokens used to
+	// authenticate with Azure DevOps must be issued for.
+	AzureDevOpsResourceId = "499b84ac-1321-427f-aa17-267ca6975798"
+
+	DefaultAuthorityHost = "https://login.microsoftonline.com"
+)
+
+// AccessToken is a bearer token issued by Microsoft Entra ID.
+type AccessToken struct {
+	Token     string
+	ExpiresOn time.Time
+}
+
+// TokenCredential acquires access tokens for Azure DevOps from Microsoft Entra ID.
+type TokenCredential interface {
+	Token(ctx context.Context) (AccessToken, error)
+}
+
+// tokenRefreshMargin is how long before an access token expires that a new one is acquired, so that requests do not
+// fail part way through.
+const tokenRefreshMargin = 5 * time.Minute
+
+// cachedCredential reuses the access token acquired from a credential until it is about to expire.
+type cachedCredential struct {
+	credential TokenCredential
+	now        func() time.Time
+
+	mutex sync.Mutex
+	token AccessToken
+}
+
+func (c *cachedCredential) Token(ctx context.Context) (AccessToken, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token.Token != "" && c.now().Add(tokenRefreshMargin).Before(c.token.ExpiresOn) {
+		return c.token, nil
+	}
+
+	token, err := c.credential.Token(ctx)
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	c.token = token
+	return token, nil
+}
+
+// tokenResponse is the response of the Microsoft identity platform token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// tokenEndpoint returns the URL of the token endpoint of the given tenant.
+func tokenEndpoint(authorityHost string, tenantId string) string {
+	if authorityHost == "" {
+		authorityHost = DefaultAuthorityHost
+	}
+	return fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimRight(authorityHost, "/"), url.PathEscape(tenantId))
+}
+
+// requestToken requests an access token for Azure DevOps from a token endpoint using the client credentials grant.
+func requestToken(
+	ctx context.Context, httpClient *http.Client, endpoint string, form url.Values,
+) (AccessToken, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", AzureDevOpsResourceId+"/.default")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return AccessToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	requestedAt := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("error requesting access token from %s: %v", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("error reading access token response from %s: %v", endpoint, err)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return AccessToken{}, fmt.Errorf(
+			"unexpected response from %s (%s): %s", endpoint, resp.Status, strings.TrimSpace(string(body)),
+		)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		if token.Error != "" {
+			return AccessToken{}, fmt.Errorf(
+				"%s returned %s: %s: %s", endpoint, resp.Status, token.Error, token.ErrorDescription,
+			)
+		}
+		return AccessToken{}, fmt.Errorf("%s returned %s without an access token", endpoint, resp.Status)
+	}
+
+	return AccessToken{
+		Token:     token.AccessToken,
+		ExpiresOn: requestedAt.Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultIMDSEndpoint is the token endpoint of the Azure Instance Metadata Service, which is reachable from virtual
+	// machines and scale sets in Azure.
+	DefaultIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+	// managedIdentityTimeout bounds each request to the identity endpoint, which is local to the host and either
+	// responds promptly or is not reachable at all, such as when not running in Azure.
+	managedIdentityTimeout = 10 * time.Second
+)
+
+// ManagedIdentityCredential acquires access tokens for the managed identity of the Azure resource Terraform is running
+// on, from either the Azure Instance Metadata Service or the identity endpoint of App Service and similar hosts.
+type ManagedIdentityCredential struct {
+	// Endpoint is the URL of the identity endpoint.
+	Endpoint string
+	// IdentityHeader is the secret App Service requires requests to its identity endpoint to present. The endpoint is
+	// treated as the Azure Instance Metadata Service when this is empty.
+	IdentityHeader string
+	// ClientId selects a user-assigned managed identity, or the system-assigned managed identity when empty.
+	ClientId   string
+	HTTPClient *http.Client
+}
+
+var _ TokenCredential = &ManagedIdentityCredential{}
+
+func NewManagedIdentityCredential(endpoint string, identityHeader string, clientId string) *ManagedIdentityCredential {
+	if endpoint == "" {
+		endpoint = DefaultIMDSEndpoint
+	}
+	return &ManagedIdentityCredential{
+		Endpoint:       endpoint,
+		IdentityHeader: identityHeader,
+		ClientId:       clientId,
+	}
+}
+
+// managedIdentityTokenResponse is the response of an identity endpoint, which, unlike the Microsoft identity platform
+// token endpoint, may encode the expiry as strings.
+type managedIdentityTokenResponse struct {
+	AccessToken      string          `json:"access_token"`
+	ExpiresIn        json.RawMessage `json:"expires_in"`
+	ExpiresOn        json.RawMessage `json:"expires_on"`
+	Error            string          `json:"error"`
+	ErrorDescription string          `json:"error_description"`
+}
+
+func (c *ManagedIdentityCredential) Token(ctx context.Context) (AccessToken, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("error parsing managed identity endpoint: %v", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("resource", AzureDevOpsResourceId)
+	if c.ClientId != "" {
+		query.Set("client_id", c.ClientId)
+	}
+	if c.IdentityHeader != "" {
+		query.Set("api-version", "2019-08-01")
+	} else {
+		query.Set("api-version", "2018-02-01")
+	}
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	if c.IdentityHeader != "" {
+		req.Header.Set("X-IDENTITY-HEADER", c.IdentityHeader)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: managedIdentityTimeout}
+	}
+
+	requestedAt := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("error requesting access token from %s: %v", endpoint.Host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("error reading access token response from %s: %v", endpoint.Host, err)
+	}
+
+	var token managedIdentityTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return AccessToken{}, fmt.Errorf(
+			"unexpected response from %s (%s): %s", endpoint.Host, resp.Status, strings.TrimSpace(string(body)),
+		)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		if token.Error != "" {
+			return AccessToken{}, fmt.Errorf(
+				"%s returned %s: %s: %s", endpoint.Host, resp.Status, token.Error, token.ErrorDescription,
+			)
+		}
+		return AccessToken{}, fmt.Errorf("%s returned %s without an access token", endpoint.Host, resp.Status)
+	}
+
+	accessToken := AccessToken{Token: token.AccessToken}
+	if expiresOn, ok := parseJsonInt(token.ExpiresOn); ok {
+		accessToken.ExpiresOn = time.Unix(expiresOn, 0)
+	} else if expiresIn, ok := parseJsonInt(token.ExpiresIn); ok {
+		accessToken.ExpiresOn = requestedAt.Add(time.Duration(expiresIn) * time.Second)
+	} else {
+		return AccessToken{}, fmt.Errorf("%s returned an access token without an expiry", endpoint.Host)
+	}
+
+	return accessToken, nil
+}
+
+// parseJsonInt parses an integer encoded as either a JSON number or a string.
+func parseJsonInt(raw json.RawMessage) (int64, bool) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		value = string(raw)
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	return parsed, err == nil
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestIdentityEndpoint stands in for the Azure Instance Metadata Service, or the App Service identity endpoint when
+// given an identity header, responding to each token request with the given response.
+func newTestIdentityEndpoint(t *testing.T, identityHeader string, response map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodGet, r.Method)
+				require.Equal(t, AzureDevOpsResourceId, r.URL.Query().Get("resource"))
+				if identityHeader != "" {
+					require.Equal(t, "2019-08-01", r.URL.Query().Get("api-version"))
+					require.Equal(t, identityHeader, r.Header.Get("X-IDENTITY-HEADER"))
+				} else {
+					require.Equal(t, "2018-02-01", r.URL.Query().Get("api-version"))
+					require.Equal(t, "true", r.Header.Get("Metadata"))
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Query().Get("client_id") == "unknown" {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(
+						map[string]interface{}{
+							"error": "invalid_request", "error_description": "Identity not found",
+						},
+					)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(response)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestManagedIdentityCredential(t *testing.T) {
+	expiresOn := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	test := func(
+		identityHeader string, clientId string, response map[string]interface{}, expectedErr string,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			server := newTestIdentityEndpoint(t, identityHeader, response)
+
+			credential := NewManagedIdentityCredential(server.URL+"/msi/token", identityHeader, clientId)
+			token, err := credential.Token(context.Background())
+			if expectedErr != "" {
+				require.ErrorContains(t, err, expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "some-token", token.Token)
+			require.WithinDuration(t, expiresOn, token.ExpiresOn, time.Minute)
+		}
+	}
+
+	imdsResponse := map[string]interface{}{
+		"access_token": "some-token",
+		"expires_in":   "3600",
+		"expires_on":   strconv.FormatInt(expiresOn.Unix(), 10),
+	}
+
+	t.Run("imds", test("", "", imdsResponse, ""))
+	t.Run("imds_user_assigned", test("", "00000000-0000-0000-0000-000000000001", imdsResponse, ""))
+	t.Run(
+		"imds_expires_in", test(
+			"", "", map[string]interface{}{"access_token": "some-token", "expires_in": "3600"}, "",
+		),
+	)
+	t.Run(
+		"app_service", test(
+			"identity-header", "", map[string]interface{}{
+				"access_token": "some-token",
+				"expires_on":   expiresOn.Unix(),
+			}, "",
+		),
+	)
+	t.Run("unknown_identity", test("", "unknown", imdsResponse, "invalid_request: Identity not found"))
+	t.Run(
+		"missing_expiry", test(
+			"", "", map[string]interface{}{"access_token": "some-token"}, "access token without an expiry",
+		),
+	)
+}
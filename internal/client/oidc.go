@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// oidcAudience is the audience Microsoft Entra ID requires of federated tokens exchanged for access tokens.
+const oidcAudience = "api://AzureADTokenExchange"
+
+// OIDCTokenAssertion presents a federated OIDC token as the client assertion.
+func OIDCTokenAssertion(token string) ClientAssertion {
+	return func(context.Context, string) (string, error) {
+		return token, nil
+	}
+}
+
+// OIDCTokenFileAssertion presents the federated OIDC token read from a file as the client assertion. The file is read
+// each time an access token is requested, as platforms which project tokens into files rotate them before they expire.
+func OIDCTokenFileAssertion(path string) ClientAssertion {
+	return func(context.Context, string) (string, error) {
+		token, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading OIDC token file: %v", err)
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+}
+
+// GitHubActionsOIDCAssertion requests a federated OIDC token from the GitHub Actions token endpoint, given by the
+// ACTIONS_ID_TOKEN_REQUEST_URL and ACTIONS_ID_TOKEN_REQUEST_TOKEN environment variables of a job with the id-token
+// permission.
+func GitHubActionsOIDCAssertion(httpClient *http.Client, requestUrl string, requestToken string) ClientAssertion {
+	return func(ctx context.Context, _ string) (string, error) {
+		endpoint, err := url.Parse(requestUrl)
+		if err != nil {
+			return "", fmt.Errorf("error parsing OIDC request URL: %v", err)
+		}
+		query := endpoint.Query()
+		query.Set("audience", oidcAudience)
+		endpoint.RawQuery = query.Encode()
+
+		var response struct {
+			Value string `json:"value"`
+		}
+		if err := requestOIDCToken(ctx, httpClient, http.MethodGet, endpoint, requestToken, &response); err != nil {
+			return "", err
+		}
+		if response.Value == "" {
+			return "", fmt.Errorf("no OIDC token was issued by %s", endpoint.Host)
+		}
+		return response.Value, nil
+	}
+}
+
+// AzurePipelinesOIDCAssertion requests a federated OIDC token for an Azure Resource Manager service connection from
+// Azure Pipelines, given by the SYSTEM_OIDCREQUESTURI and SYSTEM_ACCESSTOKEN variables of a pipeline job.
+func AzurePipelinesOIDCAssertion(
+	httpClient *http.Client, requestUrl string, requestToken string, serviceConnectionId string,
+) ClientAssertion {
+	return func(ctx context.Context, _ string) (string, error) {
+		endpoint, err := url.Parse(requestUrl)
+		if err != nil {
+			return "", fmt.Errorf("error parsing OIDC request URL: %v", err)
+		}
+		query := endpoint.Query()
+		query.Set("api-version", "7.1")
+		query.Set("serviceConnectionId", serviceConnectionId)
+		endpoint.RawQuery = query.Encode()
+
+		var response struct {
+			OIDCToken string `json:"oidcToken"`
+		}
+		if err := requestOIDCToken(ctx, httpClient, http.MethodPost, endpoint, requestToken, &response); err != nil {
+			return "", err
+		}
+		if response.OIDCToken == "" {
+			return "", fmt.Errorf("no OIDC token was issued by %s", endpoint.Host)
+		}
+		return response.OIDCToken, nil
+	}
+}
+
+func requestOIDCToken(
+	ctx context.Context, httpClient *http.Client, method string, endpoint *url.URL, requestToken string,
+	response interface{},
+) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	req.Header.Set("Accept", "application/json")
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting OIDC token from %s: %v", endpoint.Host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading OIDC token response from %s: %v", endpoint.Host, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(
+			"%s returned %s when requesting OIDC token: %s", endpoint.Host, resp.Status, strings.TrimSpace(string(body)),
+		)
+	}
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("unexpected OIDC token response from %s: %v", endpoint.Host, err)
+	}
+
+	return nil
+}
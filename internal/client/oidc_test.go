@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCAssertions(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer request-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/github":
+					require.Equal(t, http.MethodGet, r.Method)
+					require.Equal(t, "1", r.URL.Query().Get("runtime"))
+					require.Equal(t, oidcAudience, r.URL.Query().Get("audience"))
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"value": "github-token"})
+				case "/pipelines":
+					require.Equal(t, http.MethodPost, r.Method)
+					require.Equal(t, "00000000-0000-0000-0000-000000000003", r.URL.Query().Get("serviceConnectionId"))
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"oidcToken": "pipelines-token"})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	tokenFilePath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFilePath, []byte("file-token\n"), 0600))
+
+	test := func(assertion ClientAssertion, expectedToken string, expectedErr string) func(*testing.T) {
+		return func(t *testing.T) {
+			token, err := assertion(context.Background(), "audience")
+			if expectedErr != "" {
+				require.ErrorContains(t, err, expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, expectedToken, token)
+		}
+	}
+
+	t.Run("token", test(OIDCTokenAssertion("some-token"), "some-token", ""))
+	t.Run("token_file", test(OIDCTokenFileAssertion(tokenFilePath), "file-token", ""))
+	t.Run(
+		"token_file_missing",
+		test(OIDCTokenFileAssertion(filepath.Join(t.TempDir(), "missing")), "", "error reading OIDC token file"),
+	)
+	t.Run(
+		"github_actions",
+		test(GitHubActionsOIDCAssertion(nil, server.URL+"/github?runtime=1", "request-token"), "github-token", ""),
+	)
+	t.Run(
+		"azure_pipelines", test(
+			AzurePipelinesOIDCAssertion(
+				nil, server.URL+"/pipelines", "request-token", "00000000-0000-0000-0000-000000000003",
+			), "pipelines-token", "",
+		),
+	)
+	t.Run(
+		"unauthorized", test(
+			GitHubActionsOIDCAssertion(nil, server.URL+"/github", "wrong"), "", "401 Unauthorized",
+		),
+	)
+}
+
+func TestClientAssertionCredential(t *testing.T) {
+	server := newTestTokenEndpoint(
+		t, "some-token", func(form url.Values) string {
+			require.Equal(t, testClientId, form.Get("client_id"))
+			require.Equal(t, clientAssertionType, form.Get("client_assertion_type"))
+			if form.Get("client_assertion") != "federated-token" {
+				return "invalid_client"
+			}
+			return ""
+		},
+	)
+
+	credential := NewClientAssertionCredential(
+		server.URL, testTenantId, testClientId, OIDCTokenAssertion("federated-token"),
+	)
+	token, err := credential.Token(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "some-token", token.Token)
+}
@@ -0,0 +1,170 @@
+package securityroles
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+)
+
+const (
+	ApiVersion60Preview1 = "6.0-preview.1"
+)
+
+type Client interface {
+	GetRoleAssignments(context.Context, GetRoleAssignmentsArgs) (*[]RoleAssignment, error)
+	RemoveRoleAssignments(context.Context, RemoveRoleAssignmentsArgs) error
+	SetInheritPermissions(context.Context, SetInheritPermissionsArgs) error
+	SetRoleAssignments(context.Context, SetRoleAssignmentsArgs) (*[]RoleAssignment, error)
+}
+
+// ClientImpl talks to the security roles API, which is not registered as a resource location on the server and so is
+// addressed directly relative to the organisation URL.
+type ClientImpl struct {
+	Client  azuredevops.Client
+	baseUrl string
+}
+
+func NewClient(_ context.Context, connection *azuredevops.Connection) Client {
+	return &ClientImpl{
+		Client:  *azuredevops.NewClient(connection, connection.BaseUrl),
+		baseUrl: strings.TrimRight(connection.BaseUrl, "/"),
+	}
+}
+
+func (client *ClientImpl) GetRoleAssignments(ctx context.Context, args GetRoleAssignmentsArgs) (
+	*[]RoleAssignment, error,
+) {
+	if args.ScopeId == nil || *args.ScopeId == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ScopeId"}
+	}
+	if args.ResourceId == nil || *args.ResourceId == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ResourceId"}
+	}
+
+	resp, err := client.send(ctx, http.MethodGet, *args.ScopeId, *args.ResourceId, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var responseValue []RoleAssignment
+	err = client.Client.UnmarshalCollectionBody(resp, &responseValue)
+	return &responseValue, err
+}
+
+type GetRoleAssignmentsArgs struct {
+	ScopeId    *string
+	ResourceId *string
+}
+
+func (client *ClientImpl) RemoveRoleAssignments(ctx context.Context, args RemoveRoleAssignmentsArgs) error {
+	if args.ScopeId == nil || *args.ScopeId == "" {
+		return &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ScopeId"}
+	}
+	if args.ResourceId == nil || *args.ResourceId == "" {
+		return &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ResourceId"}
+	}
+	if args.IdentityIds == nil {
+		return &azuredevops.ArgumentNilError{ArgumentName: "args.IdentityIds"}
+	}
+
+	_, err := client.send(ctx, http.MethodPatch, *args.ScopeId, *args.ResourceId, nil, args.IdentityIds)
+	return err
+}
+
+type RemoveRoleAssignmentsArgs struct {
+	ScopeId     *string
+	ResourceId  *string
+	IdentityIds *[]uuid.UUID
+}
+
+func (client *ClientImpl) SetInheritPermissions(ctx context.Context, args SetInheritPermissionsArgs) error {
+	if args.ScopeId == nil || *args.ScopeId == "" {
+		return &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ScopeId"}
+	}
+	if args.ResourceId == nil || *args.ResourceId == "" {
+		return &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ResourceId"}
+	}
+	if args.InheritPermissions == nil {
+		return &azuredevops.ArgumentNilError{ArgumentName: "args.InheritPermissions"}
+	}
+
+	queryParams := url.Values{}
+	queryParams.Add("inheritPermissions", strconv.FormatBool(*args.InheritPermissions))
+
+	_, err := client.send(ctx, http.MethodPatch, *args.ScopeId, *args.ResourceId, queryParams, []uuid.UUID{})
+	return err
+}
+
+type SetInheritPermissionsArgs struct {
+	ScopeId            *string
+	ResourceId         *string
+	InheritPermissions *bool
+}
+
+func (client *ClientImpl) SetRoleAssignments(ctx context.Context, args SetRoleAssignmentsArgs) (
+	*[]RoleAssignment, error,
+) {
+	if args.ScopeId == nil || *args.ScopeId == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ScopeId"}
+	}
+	if args.ResourceId == nil || *args.ResourceId == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.ResourceId"}
+	}
+	if args.RoleAssignments == nil {
+		return nil, &azuredevops.ArgumentNilError{ArgumentName: "args.RoleAssignments"}
+	}
+
+	resp, err := client.send(ctx, http.MethodPut, *args.ScopeId, *args.ResourceId, nil, args.RoleAssignments)
+	if err != nil {
+		return nil, err
+	}
+
+	var responseValue []RoleAssignment
+	err = client.Client.UnmarshalCollectionBody(resp, &responseValue)
+	return &responseValue, err
+}
+
+type SetRoleAssignmentsArgs struct {
+	ScopeId         *string
+	ResourceId      *string
+	RoleAssignments *[]UserRoleAssignmentRef
+}
+
+func (client *ClientImpl) send(
+	ctx context.Context, httpMethod string, scopeId string, resourceId string, queryParams url.Values,
+	body interface{},
+) (*http.Response, error) {
+	fullUrl := client.baseUrl + "/_apis/securityroles/scopes/" + url.PathEscape(scopeId) +
+		"/roleassignments/resources/" + url.PathEscape(resourceId)
+	if len(queryParams) > 0 {
+		fullUrl += "?" + queryParams.Encode()
+	}
+
+	mediaType := ""
+	var bodyReader io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(bodyJson)
+		mediaType = azuredevops.MediaTypeApplicationJson
+	}
+
+	req, err := client.Client.CreateRequestMessage(
+		ctx, httpMethod, fullUrl, ApiVersion60Preview1, bodyReader, mediaType, azuredevops.MediaTypeApplicationJson, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Client.SendRequest(req)
+}
@@ -0,0 +1,168 @@
+package securityroles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testScopeId    = "distributedtask.securefile"
+	testResourceId = "00000000-0000-0000-0000-000000000001$00000000-0000-0000-0000-000000000002"
+	testPath       = "/_apis/securityroles/scopes/distributedtask.securefile/roleassignments/resources/" +
+		"00000000-0000-0000-0000-000000000001$00000000-0000-0000-0000-000000000002"
+)
+
+var testIdentityId = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(context.Background(), azuredevops.NewPatConnection(server.URL, "pat"))
+}
+
+func writeRoleAssignments(t *testing.T, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", azuredevops.MediaTypeApplicationJson)
+	err := json.NewEncoder(w).Encode(
+		map[string]interface{}{
+			"count": 1,
+			"value": []map[string]interface{}{
+				{
+					"access":   "assigned",
+					"identity": map[string]interface{}{"id": testIdentityId.String()},
+					"role":     map[string]interface{}{"name": "User"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+}
+
+func requireRoleAssignments(t *testing.T, roleAssignments *[]RoleAssignment) {
+	require.Len(t, *roleAssignments, 1)
+	require.Equal(t, RoleAccessValues.Assigned, *(*roleAssignments)[0].Access)
+	require.Equal(t, testIdentityId.String(), *(*roleAssignments)[0].Identity.Id)
+	require.Equal(t, "User", *(*roleAssignments)[0].Role.Name)
+}
+
+func TestGetRoleAssignments(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, testPath, r.URL.Path)
+			writeRoleAssignments(t, w)
+		},
+	)
+
+	scopeId, resourceId := testScopeId, testResourceId
+	roleAssignments, err := client.GetRoleAssignments(
+		context.Background(), GetRoleAssignmentsArgs{ScopeId: &scopeId, ResourceId: &resourceId},
+	)
+	require.NoError(t, err)
+	requireRoleAssignments(t, roleAssignments)
+}
+
+func TestSetRoleAssignments(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPut, r.Method)
+			require.Equal(t, testPath, r.URL.Path)
+
+			var body []map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(
+				t, []map[string]interface{}{{"roleName": "User", "userId": testIdentityId.String()}}, body,
+			)
+
+			writeRoleAssignments(t, w)
+		},
+	)
+
+	scopeId, resourceId, roleName := testScopeId, testResourceId, "User"
+	roleAssignments, err := client.SetRoleAssignments(
+		context.Background(), SetRoleAssignmentsArgs{
+			ScopeId:    &scopeId,
+			ResourceId: &resourceId,
+			RoleAssignments: &[]UserRoleAssignmentRef{
+				{RoleName: &roleName, UserId: &testIdentityId},
+			},
+		},
+	)
+	require.NoError(t, err)
+	requireRoleAssignments(t, roleAssignments)
+}
+
+func TestRemoveRoleAssignments(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPatch, r.Method)
+			require.Equal(t, testPath, r.URL.Path)
+			require.Empty(t, r.URL.RawQuery)
+
+			var body []string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, []string{testIdentityId.String()}, body)
+
+			w.WriteHeader(http.StatusNoContent)
+		},
+	)
+
+	scopeId, resourceId := testScopeId, testResourceId
+	err := client.RemoveRoleAssignments(
+		context.Background(), RemoveRoleAssignmentsArgs{
+			ScopeId:     &scopeId,
+			ResourceId:  &resourceId,
+			IdentityIds: &[]uuid.UUID{testIdentityId},
+		},
+	)
+	require.NoError(t, err)
+}
+
+func TestSetInheritPermissions(t *testing.T) {
+	test := func(inheritPermissions bool, expectedQuery string) func(*testing.T) {
+		return func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPatch, r.Method)
+					require.Equal(t, testPath, r.URL.Path)
+					require.Equal(t, expectedQuery, r.URL.RawQuery)
+					w.WriteHeader(http.StatusNoContent)
+				},
+			)
+
+			scopeId, resourceId := testScopeId, testResourceId
+			err := client.SetInheritPermissions(
+				context.Background(), SetInheritPermissionsArgs{
+					ScopeId:            &scopeId,
+					ResourceId:         &resourceId,
+					InheritPermissions: &inheritPermissions,
+				},
+			)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("inherit", test(true, "inheritPermissions=true"))
+	t.Run("do_not_inherit", test(false, "inheritPermissions=false"))
+}
+
+func TestGetRoleAssignmentsError(t *testing.T) {
+	client := newTestClient(
+		t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	)
+
+	scopeId, resourceId := testScopeId, testResourceId
+	_, err := client.GetRoleAssignments(
+		context.Background(), GetRoleAssignmentsArgs{ScopeId: &scopeId, ResourceId: &resourceId},
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package securityroles
+
+import (
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
+)
+
+type RoleAccess string
+
+type roleAccessValuesType struct {
+	Assigned  RoleAccess
+	Inherited RoleAccess
+}
+
+var RoleAccessValues = roleAccessValuesType{
+	// Access has been explicitly assigned on the resource.
+	Assigned: "assigned",
+	// Access has been inherited from a parent scope.
+	Inherited: "inherited",
+}
+
+type RoleAssignment struct {
+	Access            *RoleAccess         `json:"access,omitempty"`
+	AccessDisplayName *string             `json:"accessDisplayName,omitempty"`
+	Identity          *webapi.IdentityRef `json:"identity,omitempty"`
+	Role              *SecurityRole       `json:"role,omitempty"`
+	Scope             *string             `json:"scope,omitempty"`
+}
+
+type SecurityRole struct {
+	AllowPermissions *int    `json:"allowPermissions,omitempty"`
+	DenyPermissions  *int    `json:"denyPermissions,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	DisplayName      *string `json:"displayName,omitempty"`
+	Identifier       *string `json:"identifier,omitempty"`
+	Name             *string `json:"name,omitempty"`
+	Scope            *string `json:"scope,omitempty"`
+}
+
+type UserRoleAssignmentRef struct {
+	RoleName   *string    `json:"roleName,omitempty"`
+	UniqueName *string    `json:"uniqueName,omitempty"`
+	UserId     *uuid.UUID `json:"userId,omitempty"`
+}
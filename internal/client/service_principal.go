@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// clientAssertionType is the type of the signed JWT presented in place of a client secret.
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ServicePrincipalCredential acquires access tokens for a Microsoft Entra ID service principal, authenticating with
+// either a client secret or a client assertion, such as one signed with a client certificate.
+type ServicePrincipalCredential struct {
+	AuthorityHost string
+	TenantId      string
+	ClientId      string
+	HTTPClient    *http.Client
+
+	clientSecret string
+	assertion    ClientAssertion
+}
+
+// ClientAssertion returns a JWT which the token endpoint with the given URL accepts in place of a client secret.
+type ClientAssertion func(ctx context.Context, audience string) (string, error)
+
+var _ TokenCredential = &ServicePrincipalCredential{}
+
+func NewClientSecretCredential(
+	authorityHost string, tenantId string, clientId string, clientSecret string,
+) *ServicePrincipalCredential {
+	return &ServicePrincipalCredential{
+		AuthorityHost: authorityHost,
+		TenantId:      tenantId,
+		ClientId:      clientId,
+		clientSecret:  clientSecret,
+	}
+}
+
+// NewClientCertificateCredential creates a credential which authenticates with a certificate and its RSA private key,
+// given either PEM encoded or as a PKCS#12 archive.
+func NewClientCertificateCredential(
+	authorityHost string, tenantId string, clientId string, certificate []byte, password string,
+) (*ServicePrincipalCredential, error) {
+	parsedCertificate, privateKey, err := parseClientCertificate(certificate, password)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion := func(_ context.Context, audience string) (string, error) {
+		return certificateAssertion(clientId, parsedCertificate, privateKey, audience, time.Now())
+	}
+
+	return NewClientAssertionCredential(authorityHost, tenantId, clientId, assertion), nil
+}
+
+func NewClientAssertionCredential(
+	authorityHost string, tenantId string, clientId string, assertion ClientAssertion,
+) *ServicePrincipalCredential {
+	return &ServicePrincipalCredential{
+		AuthorityHost: authorityHost,
+		TenantId:      tenantId,
+		ClientId:      clientId,
+		assertion:     assertion,
+	}
+}
+
+func (c *ServicePrincipalCredential) Token(ctx context.Context) (AccessToken, error) {
+	endpoint := tokenEndpoint(c.AuthorityHost, c.TenantId)
+
+	form := url.Values{}
+	form.Set("client_id", c.ClientId)
+	if c.assertion != nil {
+		assertion, err := c.assertion(ctx, endpoint)
+		if err != nil {
+			return AccessToken{}, fmt.Errorf("error creating client assertion: %v", err)
+		}
+		form.Set("client_assertion_type", clientAssertionType)
+		form.Set("client_assertion", assertion)
+	} else {
+		form.Set("client_secret", c.clientSecret)
+	}
+
+	return requestToken(ctx, c.HTTPClient, endpoint, form)
+}
+
+// certificateAssertion creates a client assertion signed with the private key of a client certificate.
+func certificateAssertion(
+	clientId string, certificate *x509.Certificate, privateKey *rsa.PrivateKey, audience string, now time.Time,
+) (string, error) {
+	thumbprint := sha1.Sum(certificate.Raw)
+	header, err := json.Marshal(
+		map[string]interface{}{
+			"alg": "RS256",
+			"typ": "JWT",
+			"x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(
+		map[string]interface{}{
+			"aud": audience,
+			"iss": clientId,
+			"sub": clientId,
+			"jti": uuid.New().String(),
+			"nbf": now.Unix(),
+			"iat": now.Unix(),
+			"exp": now.Add(10 * time.Minute).Unix(),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseClientCertificate parses a client certificate and its private key from PEM blocks, or from a PKCS#12 archive
+// when the data contains no PEM blocks.
+func parseClientCertificate(data []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	var certificate *x509.Certificate
+	var privateKey interface{}
+
+	block, rest := pem.Decode(data)
+	if block == nil {
+		var err error
+		privateKey, certificate, _, err = pkcs12.DecodeChain(data, password)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decoding PKCS#12 client certificate: %w", err)
+		}
+	}
+	for ; block != nil; block, rest = pem.Decode(rest) {
+		var err error
+		switch block.Type {
+		case "CERTIFICATE":
+			if certificate == nil {
+				certificate, err = x509.ParseCertificate(block.Bytes)
+			}
+		case "PRIVATE KEY":
+			privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing PEM encoded client certificate: %v", err)
+		}
+	}
+
+	if certificate == nil {
+		return nil, nil, errors.New("client certificate does not contain a certificate")
+	}
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("client certificate does not contain an RSA private key")
+	}
+
+	return certificate, rsaPrivateKey, nil
+}
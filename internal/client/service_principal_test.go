@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	testTenantId = "00000000-0000-0000-0000-000000000001"
+	testClientId = "00000000-0000-0000-0000-000000000002"
+)
+
+// newTestTokenEndpoint stands in for the Microsoft identity platform, passing the form of each token request to check
+// and issuing the given access token when it returns no error.
+func newTestTokenEndpoint(t *testing.T, accessToken string, check func(url.Values) string) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "/"+testTenantId+"/oauth2/v2.0/token", r.URL.Path)
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+				require.Equal(t, AzureDevOpsResourceId+"/.default", r.PostForm.Get("scope"))
+
+				w.Header().Set("Content-Type", "application/json")
+				if errorCode := check(r.PostForm); errorCode != "" {
+					w.WriteHeader(http.StatusUnauthorized)
+					_ = json.NewEncoder(w).Encode(
+						map[string]interface{}{"error": errorCode, "error_description": "AADSTS7000215: Invalid client."},
+					)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(
+					map[string]interface{}{"token_type": "Bearer", "expires_in": 3599, "access_token": accessToken},
+				)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClientCertificate(t *testing.T) (*x509.Certificate, *rsa.PrivateKey) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate, privateKey
+}
+
+func TestClientSecretCredential(t *testing.T) {
+	test := func(clientSecret string, expectedErr string) func(*testing.T) {
+		return func(t *testing.T) {
+			server := newTestTokenEndpoint(
+				t, "some-token", func(form url.Values) string {
+					require.Equal(t, testClientId, form.Get("client_id"))
+					if form.Get("client_secret") != "secret" {
+						return "invalid_client"
+					}
+					return ""
+				},
+			)
+
+			credential := NewClientSecretCredential(server.URL, testTenantId, testClientId, clientSecret)
+			token, err := credential.Token(context.Background())
+			if expectedErr != "" {
+				require.ErrorContains(t, err, expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "some-token", token.Token)
+			require.WithinDuration(t, time.Now().Add(3599*time.Second), token.ExpiresOn, time.Minute)
+		}
+	}
+
+	t.Run("valid", test("secret", ""))
+	t.Run("invalid", test("wrong", "invalid_client: AADSTS7000215: Invalid client."))
+}
+
+func TestClientCertificateCredential(t *testing.T) {
+	certificate, privateKey := newTestClientCertificate(t)
+
+	test := func(data []byte, password string) func(*testing.T) {
+		return func(t *testing.T) {
+			var endpoint string
+			server := newTestTokenEndpoint(
+				t, "some-token", func(form url.Values) string {
+					require.Equal(t, testClientId, form.Get("client_id"))
+					require.Equal(t, clientAssertionType, form.Get("client_assertion_type"))
+					require.Empty(t, form.Get("client_secret"))
+
+					parts := strings.Split(form.Get("client_assertion"), ".")
+					require.Len(t, parts, 3)
+
+					var header, claims map[string]interface{}
+					decodeJwtPart(t, parts[0], &header)
+					decodeJwtPart(t, parts[1], &claims)
+
+					thumbprint := sha1.Sum(certificate.Raw)
+					require.Equal(t, "RS256", header["alg"])
+					require.Equal(t, base64.RawURLEncoding.EncodeToString(thumbprint[:]), header["x5t"])
+					require.Equal(t, endpoint, claims["aud"])
+					require.Equal(t, testClientId, claims["iss"])
+					require.Equal(t, testClientId, claims["sub"])
+
+					signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+					require.NoError(t, err)
+					digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+					require.NoError(t, rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature))
+					return ""
+				},
+			)
+			endpoint = server.URL + "/" + testTenantId + "/oauth2/v2.0/token"
+
+			credential, err := NewClientCertificateCredential(server.URL, testTenantId, testClientId, data, password)
+			require.NoError(t, err)
+
+			token, err := credential.Token(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, "some-token", token.Token)
+		}
+	}
+
+	pkcs8PrivateKey, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	pemData := append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8PrivateKey})...,
+	)
+	pkcs1Data := append(
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})...,
+	)
+	pkcs12Data, err := pkcs12.Modern.Encode(privateKey, certificate, nil, "secret")
+	require.NoError(t, err)
+
+	t.Run("pem", test(pemData, ""))
+	t.Run("pem_pkcs1", test(pkcs1Data, ""))
+	t.Run("pkcs12", test(pkcs12Data, "secret"))
+
+	t.Run(
+		"pkcs12_incorrect_password", func(t *testing.T) {
+			_, err := NewClientCertificateCredential("", testTenantId, testClientId, pkcs12Data, "wrong")
+			require.ErrorIs(t, err, pkcs12.ErrIncorrectPassword)
+		},
+	)
+	t.Run(
+		"missing_private_key", func(t *testing.T) {
+			data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+			_, err := NewClientCertificateCredential("", testTenantId, testClientId, data, "")
+			require.ErrorContains(t, err, "does not contain an RSA private key")
+		},
+	)
+}
+
+func decodeJwtPart(t *testing.T, part string, v interface{}) {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
@@ -24,7 +27,11 @@ var (
 
 type Client interface {
 	DeleteSecureFile(context.Context, DeleteSecureFileArgs) error
+	DownloadSecureFile(context.Context, DownloadSecureFileArgs) (io.ReadCloser, error)
 	GetSecureFile(context.Context, GetSecureFileArgs) (*SecureFile, error)
+	GetSecureFiles(context.Context, GetSecureFilesArgs) (*[]SecureFile, error)
+	GetSecureFilesByIds(context.Context, GetSecureFilesByIdsArgs) (*[]SecureFile, error)
+	GetSecureFilesByNames(context.Context, GetSecureFilesByNamesArgs) (*[]SecureFile, error)
 	UpdateSecureFile(context.Context, UpdateSecureFileArgs) (*SecureFile, error)
 	UploadSecureFile(context.Context, UploadSecureFileArgs) (*SecureFile, error)
 }
@@ -32,12 +39,25 @@ type Client interface {
 type ClientImpl taskagent.ClientImpl
 
 func NewClient(ctx context.Context, connection *azuredevops.Connection) (Client, error) {
-	internalClient, err := taskagent.NewClient(ctx, connection)
+	return NewClientWithTransport(ctx, connection, nil)
+}
+
+// NewClientWithTransport creates a client which sends requests using the given transport, such as one which
+// authenticates each request, in place of the transport azuredevops.NewClient would use for the connection.
+func NewClientWithTransport(
+	ctx context.Context, connection *azuredevops.Connection, transport http.RoundTripper,
+) (Client, error) {
+	locationUrl, err := getResourceAreaLocationUrl(ctx, connection, taskagent.ResourceAreaId)
 	if err != nil {
 		return nil, err
 	}
-	clientImpl := ClientImpl(*internalClient.(*taskagent.ClientImpl))
-	return &clientImpl, nil
+
+	// The client is constructed directly rather than through taskagent.NewClient so that uploads are able to specify
+	// the length of a streamed request body.
+	internalClient := azuredevops.NewClientWithOptions(
+		connection, locationUrl, azuredevops.WithHTTPClient(newHTTPClient(connection, transport)),
+	)
+	return &ClientImpl{Client: *internalClient}, nil
 }
 
 func (client *ClientImpl) DeleteSecureFile(ctx context.Context, args DeleteSecureFileArgs) error {
@@ -65,6 +85,45 @@ type DeleteSecureFileArgs struct {
 	SecureFileId *uuid.UUID
 }
 
+func (client *ClientImpl) DownloadSecureFile(ctx context.Context, args DownloadSecureFileArgs) (
+	io.ReadCloser, error,
+) {
+	if args.Project == nil || *args.Project == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Project"}
+	}
+	if args.SecureFileId == nil {
+		return nil, &azuredevops.ArgumentNilError{ArgumentName: "args.SecureFileId"}
+	}
+	if args.Ticket == nil || *args.Ticket == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Ticket"}
+	}
+
+	routeValues := make(map[string]string)
+	routeValues["project"] = *args.Project
+	routeValues["secureFileId"] = (*args.SecureFileId).String()
+
+	queryParams := url.Values{}
+	queryParams.Add("ticket", *args.Ticket)
+	queryParams.Add("download", "true")
+
+	resp, err := client.Client.Send(
+		ctx, http.MethodGet, SecureFilesLocationId, ApiVersion50, routeValues, queryParams, nil, "",
+		MediaTypeApplicationOctetStream, nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, err
+}
+
+type DownloadSecureFileArgs struct {
+	Project      *string
+	SecureFileId *uuid.UUID
+	Ticket       *string
+}
+
 func (client *ClientImpl) GetSecureFile(ctx context.Context, args GetSecureFileArgs) (*SecureFile, error) {
 	if args.Project == nil || *args.Project == "" {
 		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Project"}
@@ -106,6 +165,143 @@ type GetSecureFileArgs struct {
 	ActionFilter          *SecureFileActionFilter
 }
 
+func (client *ClientImpl) GetSecureFiles(ctx context.Context, args GetSecureFilesArgs) (*[]SecureFile, error) {
+	if args.Project == nil || *args.Project == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Project"}
+	}
+
+	routeValues := make(map[string]string)
+	routeValues["project"] = *args.Project
+
+	queryParams := url.Values{}
+	if args.NamePattern != nil {
+		queryParams.Add("namePattern", *args.NamePattern)
+	}
+	if args.IncludeDownloadTickets != nil {
+		queryParams.Add("includeDownloadTickets", strconv.FormatBool(*args.IncludeDownloadTickets))
+	}
+	if args.ActionFilter != nil {
+		queryParams.Add("actionFilter", (string)(*args.ActionFilter))
+	}
+
+	resp, err := client.Client.Send(
+		ctx, http.MethodGet, SecureFilesLocationId, ApiVersion50, routeValues, queryParams, nil, "",
+		azuredevops.MediaTypeApplicationJson, nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var responseValue []SecureFile
+	err = client.Client.UnmarshalCollectionBody(resp, &responseValue)
+	return &responseValue, err
+}
+
+type GetSecureFilesArgs struct {
+	Project                *string
+	NamePattern            *string
+	IncludeDownloadTickets *bool
+	ActionFilter           *SecureFileActionFilter
+}
+
+func (client *ClientImpl) GetSecureFilesByIds(ctx context.Context, args GetSecureFilesByIdsArgs) (
+	*[]SecureFile, error,
+) {
+	if args.Project == nil || *args.Project == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Project"}
+	}
+	if args.SecureFileIds == nil {
+		return nil, &azuredevops.ArgumentNilError{ArgumentName: "args.SecureFileIds"}
+	}
+
+	routeValues := make(map[string]string)
+	routeValues["project"] = *args.Project
+
+	secureFileIds := make([]string, len(*args.SecureFileIds))
+	for i, secureFileId := range *args.SecureFileIds {
+		secureFileIds[i] = secureFileId.String()
+	}
+
+	queryParams := url.Values{}
+	queryParams.Add("secureFileIds", strings.Join(secureFileIds, ","))
+	if args.IncludeDownloadTickets != nil {
+		queryParams.Add("includeDownloadTickets", strconv.FormatBool(*args.IncludeDownloadTickets))
+	}
+	if args.ActionFilter != nil {
+		queryParams.Add("actionFilter", (string)(*args.ActionFilter))
+	}
+
+	resp, err := client.Client.Send(
+		ctx, http.MethodGet, SecureFilesLocationId, ApiVersion50, routeValues, queryParams, nil, "",
+		azuredevops.MediaTypeApplicationJson, nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var responseValue []SecureFile
+	err = client.Client.UnmarshalCollectionBody(resp, &responseValue)
+	return &responseValue, err
+}
+
+type GetSecureFilesByIdsArgs struct {
+	Project                *string
+	SecureFileIds          *[]uuid.UUID
+	IncludeDownloadTickets *bool
+	ActionFilter           *SecureFileActionFilter
+}
+
+func (client *ClientImpl) GetSecureFilesByNames(ctx context.Context, args GetSecureFilesByNamesArgs) (
+	*[]SecureFile, error,
+) {
+	if args.Project == nil || *args.Project == "" {
+		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Project"}
+	}
+	if args.SecureFileNames == nil {
+		return nil, &azuredevops.ArgumentNilError{ArgumentName: "args.SecureFileNames"}
+	}
+	// The names are sent separated by commas, which cannot be escaped, so a name containing one would be split
+	for _, name := range *args.SecureFileNames {
+		if strings.Contains(name, ",") {
+			return nil, fmt.Errorf("secure file name (%s) cannot be looked up by name as it contains a comma", name)
+		}
+	}
+
+	routeValues := make(map[string]string)
+	routeValues["project"] = *args.Project
+
+	queryParams := url.Values{}
+	queryParams.Add("secureFileNames", strings.Join(*args.SecureFileNames, ","))
+	if args.IncludeDownloadTickets != nil {
+		queryParams.Add("includeDownloadTickets", strconv.FormatBool(*args.IncludeDownloadTickets))
+	}
+	if args.ActionFilter != nil {
+		queryParams.Add("actionFilter", (string)(*args.ActionFilter))
+	}
+
+	resp, err := client.Client.Send(
+		ctx, http.MethodGet, SecureFilesLocationId, ApiVersion50, routeValues, queryParams, nil, "",
+		azuredevops.MediaTypeApplicationJson, nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var responseValue []SecureFile
+	err = client.Client.UnmarshalCollectionBody(resp, &responseValue)
+	return &responseValue, err
+}
+
+type GetSecureFilesByNamesArgs struct {
+	Project                *string
+	SecureFileNames        *[]string
+	IncludeDownloadTickets *bool
+	ActionFilter           *SecureFileActionFilter
+}
+
 func (client *ClientImpl) UpdateSecureFile(ctx context.Context, args UpdateSecureFileArgs) (*SecureFile, error) {
 	if args.Project == nil || *args.Project == "" {
 		return nil, &azuredevops.ArgumentNilOrEmptyError{ArgumentName: "args.Project"}
@@ -168,9 +364,13 @@ func (client *ClientImpl) UploadSecureFile(ctx context.Context, args UploadSecur
 		queryParams.Add("authorizePipelines", strconv.FormatBool(*args.AuthorizePipelines))
 	}
 
+	additionalHeaders := map[string]string{
+		headerKeyContentLength: strconv.FormatInt(args.ContentLength, 10),
+	}
+
 	resp, err := client.Client.Send(
 		ctx, http.MethodPost, SecureFilesLocationId, ApiVersion50, routeValues, queryParams,
-		bytes.NewReader(*args.Content), MediaTypeApplicationOctetStream, azuredevops.MediaTypeApplicationJson, nil,
+		args.Content, MediaTypeApplicationOctetStream, azuredevops.MediaTypeApplicationJson, additionalHeaders,
 	)
 	if err != nil {
 		return nil, err
@@ -185,5 +385,7 @@ type UploadSecureFileArgs struct {
 	Name               *string
 	Project            *string
 	AuthorizePipelines *bool
-	Content            *[]byte
+	// Content is streamed as the request body, it must yield exactly ContentLength bytes.
+	Content       io.Reader
+	ContentLength int64
 }
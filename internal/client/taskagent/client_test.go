@@ -0,0 +1,473 @@
+package taskagent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+const testProject = "my-project"
+
+var resourceAreasLocationId = uuid.MustParse("e81700f7-3be2-46de-8624-2eb35882fcaa")
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodOptions && r.URL.Path == "/_apis":
+					writeJson(
+						t, w, map[string]interface{}{
+							"count": 2,
+							"value": []azuredevops.ApiResourceLocation{
+								{
+									Id:              &resourceAreasLocationId,
+									Area:            utils.NewString("Location"),
+									ResourceName:    utils.NewString("ResourceAreas"),
+									RouteTemplate:   utils.NewString("_apis/{resource}/{areaId}"),
+									ResourceVersion: utils.NewInt(1),
+									MinVersion:      utils.NewString("3.2"),
+									MaxVersion:      utils.NewString("7.1"),
+									ReleasedVersion: utils.NewString("0.0"),
+								},
+								{
+									Id:              &SecureFilesLocationId,
+									Area:            utils.NewString("distributedtask"),
+									ResourceName:    utils.NewString("securefiles"),
+									RouteTemplate:   utils.NewString("{project}/_apis/{area}/{resource}/{secureFileId}"),
+									ResourceVersion: utils.NewInt(1),
+									MinVersion:      utils.NewString("3.2"),
+									MaxVersion:      utils.NewString("7.1"),
+									ReleasedVersion: utils.NewString("5.0"),
+								},
+							},
+						},
+					)
+				case r.Method == http.MethodGet && r.URL.Path == "/_apis/ResourceAreas":
+					// Respond as an on-prem server would, so everything is served from the test server
+					writeJson(t, w, map[string]interface{}{"count": 0, "value": []interface{}{}})
+				default:
+					handler(w, r)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), azuredevops.NewAnonymousConnection(server.URL))
+	require.NoError(t, err)
+	return client
+}
+
+func writeJson(t *testing.T, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", azuredevops.MediaTypeApplicationJson)
+	require.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func writeSecureFiles(t *testing.T, w http.ResponseWriter, secureFiles ...SecureFile) {
+	writeJson(
+		t, w, map[string]interface{}{
+			"count": len(secureFiles),
+			"value": secureFiles,
+		},
+	)
+}
+
+func requireSecureFilesRequest(t *testing.T, r *http.Request, expectedQuery url.Values) {
+	require.Equal(t, http.MethodGet, r.Method)
+	require.Equal(t, "/"+testProject+"/_apis/distributedtask/securefiles", r.URL.Path)
+	require.Equal(t, "application/json;api-version=5.0", r.Header.Get("Accept"))
+	require.Equal(t, expectedQuery, r.URL.Query())
+}
+
+func TestGetSecureFiles(t *testing.T) {
+	secureFile := SecureFile{Id: &uuid.Nil, Name: utils.NewString("foo.txt")}
+
+	t.Run(
+		"no_filters", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					requireSecureFilesRequest(t, r, url.Values{})
+					writeSecureFiles(t, w, secureFile)
+				},
+			)
+
+			res, err := client.GetSecureFiles(
+				context.Background(), GetSecureFilesArgs{
+					Project: utils.NewString(testProject),
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, []SecureFile{secureFile}, *res)
+		},
+	)
+	t.Run(
+		"all_filters", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					requireSecureFilesRequest(
+						t, r, url.Values{
+							"namePattern":            {"foo*"},
+							"includeDownloadTickets": {"true"},
+							"actionFilter":           {"manage"},
+						},
+					)
+					writeSecureFiles(t, w)
+				},
+			)
+
+			res, err := client.GetSecureFiles(
+				context.Background(), GetSecureFilesArgs{
+					Project:                utils.NewString(testProject),
+					NamePattern:            utils.NewString("foo*"),
+					IncludeDownloadTickets: utils.NewBool(true),
+					ActionFilter:           &SecureFileActionFilterValues.Manage,
+				},
+			)
+			require.NoError(t, err)
+			require.Empty(t, *res)
+		},
+	)
+	t.Run(
+		"missing_project", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.GetSecureFiles(context.Background(), GetSecureFilesArgs{})
+			require.EqualError(t, err, "Argument args.Project can not be nil or empty")
+		},
+	)
+}
+
+func TestSecureFileActionFilterValues(t *testing.T) {
+	// The values are sent as the actionFilter query parameter, which Azure DevOps expects in lower case
+	require.Equal(t, SecureFileActionFilter("none"), SecureFileActionFilterValues.None)
+	require.Equal(t, SecureFileActionFilter("manage"), SecureFileActionFilterValues.Manage)
+	require.Equal(t, SecureFileActionFilter("use"), SecureFileActionFilterValues.Use)
+}
+
+func TestGetSecureFilesByIds(t *testing.T) {
+	fooId := uuid.MustParse("f3c9e1e1-5d4a-4b5e-9a3b-7c0e6e9c2a11")
+	barId := uuid.MustParse("0a8b6f2d-1c3e-4f5a-8b7c-9d0e1f2a3b4c")
+	secureFiles := []SecureFile{
+		{Id: &fooId, Name: utils.NewString("foo.txt")},
+		{Id: &barId, Name: utils.NewString("bar.txt")},
+	}
+
+	t.Run(
+		"ids", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					requireSecureFilesRequest(
+						t, r, url.Values{
+							"secureFileIds":          {fooId.String() + "," + barId.String()},
+							"includeDownloadTickets": {"false"},
+							"actionFilter":           {"use"},
+						},
+					)
+					writeSecureFiles(t, w, secureFiles...)
+				},
+			)
+
+			res, err := client.GetSecureFilesByIds(
+				context.Background(), GetSecureFilesByIdsArgs{
+					Project:                utils.NewString(testProject),
+					SecureFileIds:          &[]uuid.UUID{fooId, barId},
+					IncludeDownloadTickets: utils.NewBool(false),
+					ActionFilter:           &SecureFileActionFilterValues.Use,
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, secureFiles, *res)
+		},
+	)
+	t.Run(
+		"missing_ids", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.GetSecureFilesByIds(
+				context.Background(), GetSecureFilesByIdsArgs{
+					Project: utils.NewString(testProject),
+				},
+			)
+			require.EqualError(t, err, "Argument args.SecureFileIds can not be nil")
+		},
+	)
+}
+
+func TestGetSecureFilesByNames(t *testing.T) {
+	secureFiles := []SecureFile{
+		{Id: &uuid.Nil, Name: utils.NewString("foo.txt")},
+	}
+
+	t.Run(
+		"names", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					requireSecureFilesRequest(
+						t, r, url.Values{
+							"secureFileNames": {"foo.txt,bar.txt"},
+						},
+					)
+					writeSecureFiles(t, w, secureFiles...)
+				},
+			)
+
+			res, err := client.GetSecureFilesByNames(
+				context.Background(), GetSecureFilesByNamesArgs{
+					Project:         utils.NewString(testProject),
+					SecureFileNames: &[]string{"foo.txt", "bar.txt"},
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, secureFiles, *res)
+		},
+	)
+	t.Run(
+		"all_options", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					requireSecureFilesRequest(
+						t, r, url.Values{
+							"secureFileNames":        {"foo.txt"},
+							"includeDownloadTickets": {"true"},
+							"actionFilter":           {"use"},
+						},
+					)
+					writeSecureFiles(t, w, secureFiles...)
+				},
+			)
+
+			res, err := client.GetSecureFilesByNames(
+				context.Background(), GetSecureFilesByNamesArgs{
+					Project:                utils.NewString(testProject),
+					SecureFileNames:        &[]string{"foo.txt"},
+					IncludeDownloadTickets: utils.NewBool(true),
+					ActionFilter:           &SecureFileActionFilterValues.Use,
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, secureFiles, *res)
+		},
+	)
+	t.Run(
+		"error_response", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", azuredevops.MediaTypeApplicationJson)
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"message":"VS800075: The project does not exist."}`))
+				},
+			)
+
+			_, err := client.GetSecureFilesByNames(
+				context.Background(), GetSecureFilesByNamesArgs{
+					Project:         utils.NewString(testProject),
+					SecureFileNames: &[]string{"foo.txt"},
+				},
+			)
+			var wrappedErr azuredevops.WrappedError
+			require.ErrorAs(t, err, &wrappedErr)
+			require.Equal(t, http.StatusNotFound, *wrappedErr.StatusCode)
+		},
+	)
+	t.Run(
+		"missing_names", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.GetSecureFilesByNames(
+				context.Background(), GetSecureFilesByNamesArgs{
+					Project: utils.NewString(testProject),
+				},
+			)
+			require.EqualError(t, err, "Argument args.SecureFileNames can not be nil")
+		},
+	)
+	t.Run(
+		"missing_project", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.GetSecureFilesByNames(
+				context.Background(), GetSecureFilesByNamesArgs{
+					SecureFileNames: &[]string{"foo.txt"},
+				},
+			)
+			require.EqualError(t, err, "Argument args.Project can not be nil or empty")
+		},
+	)
+	t.Run(
+		"name_with_comma", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.GetSecureFilesByNames(
+				context.Background(), GetSecureFilesByNamesArgs{
+					Project:         utils.NewString(testProject),
+					SecureFileNames: &[]string{"foo.txt", "foo,bar.txt"},
+				},
+			)
+			require.EqualError(
+				t, err, "secure file name (foo,bar.txt) cannot be looked up by name as it contains a comma",
+			)
+		},
+	)
+}
+
+func TestDownloadSecureFile(t *testing.T) {
+	secureFileId := uuid.MustParse("f3c9e1e1-5d4a-4b5e-9a3b-7c0e6e9c2a11")
+
+	t.Run(
+		"download", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodGet, r.Method)
+					require.Equal(
+						t, "/"+testProject+"/_apis/distributedtask/securefiles/"+secureFileId.String(), r.URL.Path,
+					)
+					require.Equal(t, "application/octet-stream;api-version=5.0", r.Header.Get("Accept"))
+					require.Equal(t, url.Values{"ticket": {"some-ticket"}, "download": {"true"}}, r.URL.Query())
+
+					w.Header().Set("Content-Type", MediaTypeApplicationOctetStream)
+					_, _ = w.Write([]byte("Hello World"))
+				},
+			)
+
+			res, err := client.DownloadSecureFile(
+				context.Background(), DownloadSecureFileArgs{
+					Project:      utils.NewString(testProject),
+					SecureFileId: &secureFileId,
+					Ticket:       utils.NewString("some-ticket"),
+				},
+			)
+			require.NoError(t, err)
+			defer res.Close()
+
+			content, err := io.ReadAll(res)
+			require.NoError(t, err)
+			require.Equal(t, "Hello World", string(content))
+		},
+	)
+	t.Run(
+		"missing_ticket", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.DownloadSecureFile(
+				context.Background(), DownloadSecureFileArgs{
+					Project:      utils.NewString(testProject),
+					SecureFileId: &secureFileId,
+				},
+			)
+			require.EqualError(t, err, "Argument args.Ticket can not be nil or empty")
+		},
+	)
+}
+
+func TestUploadSecureFile(t *testing.T) {
+	secureFile := SecureFile{Id: &uuid.Nil, Name: utils.NewString("foo.txt")}
+
+	t.Run(
+		"streamed_content", func(t *testing.T) {
+			content := strings.Repeat("Hello World", 10000)
+
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.Equal(t, "/"+testProject+"/_apis/distributedtask/securefiles", r.URL.Path)
+					require.Equal(t, url.Values{"name": {"foo.txt"}, "authorizePipelines": {"false"}}, r.URL.Query())
+					require.Equal(t, "application/octet-stream;charset=utf-8", r.Header.Get("Content-Type"))
+					require.Equal(t, int64(len(content)), r.ContentLength)
+					require.Empty(t, r.TransferEncoding)
+
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					require.Equal(t, content, string(body))
+
+					writeJson(t, w, secureFile)
+				},
+			)
+
+			// Wrapping the reader hides its length from net/http, as would be the case for a file or decoder
+			res, err := client.UploadSecureFile(
+				context.Background(), UploadSecureFileArgs{
+					Name:               utils.NewString("foo.txt"),
+					Project:            utils.NewString(testProject),
+					AuthorizePipelines: utils.NewBool(false),
+					Content:            io.MultiReader(strings.NewReader(content)),
+					ContentLength:      int64(len(content)),
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, secureFile, *res)
+		},
+	)
+	t.Run(
+		"empty_content", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, int64(0), r.ContentLength)
+					writeJson(t, w, secureFile)
+				},
+			)
+
+			res, err := client.UploadSecureFile(
+				context.Background(), UploadSecureFileArgs{
+					Name:    utils.NewString("foo.txt"),
+					Project: utils.NewString(testProject),
+					Content: strings.NewReader(""),
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, secureFile, *res)
+		},
+	)
+	t.Run(
+		"missing_content", func(t *testing.T) {
+			client := newTestClient(
+				t, func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("no request should be sent")
+				},
+			)
+
+			_, err := client.UploadSecureFile(
+				context.Background(), UploadSecureFileArgs{
+					Name:    utils.NewString("foo.txt"),
+					Project: utils.NewString(testProject),
+				},
+			)
+			require.EqualError(t, err, "Argument args.Content can not be nil")
+		},
+	)
+}
@@ -0,0 +1,80 @@
+package taskagent
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+)
+
+const (
+	headerKeyContentLength = "Content-Length"
+)
+
+// getResourceAreaLocationUrl resolves the URL a resource area is served from, mirroring
+// azuredevops.Connection.GetClientByResourceAreaId.
+func getResourceAreaLocationUrl(
+	ctx context.Context, connection *azuredevops.Connection, resourceAreaId uuid.UUID,
+) (string, error) {
+	resourceAreas, err := connection.GetClientByUrl(connection.BaseUrl).GetResourceAreas(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// On-prem servers return an empty list, in which case everything is served from the base URL
+	if len(*resourceAreas) == 0 {
+		return connection.BaseUrl, nil
+	}
+
+	for _, resourceArea := range *resourceAreas {
+		if resourceArea.Id != nil && *resourceArea.Id == resourceAreaId && resourceArea.LocationUrl != nil {
+			return strings.ToLower(strings.TrimRight(*resourceArea.LocationUrl, "/")), nil
+		}
+	}
+
+	return "", &azuredevops.ResourceAreaIdNotRegisteredError{ResourceAreaId: resourceAreaId, Url: connection.BaseUrl}
+}
+
+// newHTTPClient creates an HTTP client equivalent to the one azuredevops.NewClient would create for the connection,
+// but which honours a Content-Length header on requests with a streamed body. Requests are sent using the given
+// transport, or the one azuredevops.NewClient would use when it is nil.
+func newHTTPClient(connection *azuredevops.Connection, transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = http.DefaultTransport
+		if connection.TlsConfig != nil {
+			transport = &http.Transport{TLSClientConfig: connection.TlsConfig}
+		}
+	}
+
+	httpClient := &http.Client{
+		Transport: &contentLengthTransport{transport: transport},
+	}
+	if connection.Timeout != nil {
+		httpClient.Timeout = *connection.Timeout
+	}
+	return httpClient
+}
+
+// contentLengthTransport moves a Content-Length header onto the request itself, as net/http otherwise ignores it and
+// sends any body it cannot determine the length of using chunked transfer encoding.
+type contentLengthTransport struct {
+	transport http.RoundTripper
+}
+
+func (t *contentLengthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if contentLength := req.Header.Get(headerKeyContentLength); contentLength != "" && req.Body != nil {
+		length, err := strconv.ParseInt(contentLength, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.Clone(req.Context())
+		req.Header.Del(headerKeyContentLength)
+		req.ContentLength = length
+	}
+
+	return t.transport.RoundTrip(req)
+}
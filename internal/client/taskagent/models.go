@@ -4,3 +4,13 @@ import "github.com/microsoft/azure-devops-go-api/azuredevops/v6/taskagent"
 
 type SecureFile taskagent.SecureFile
 type SecureFileActionFilter taskagent.SecureFileActionFilter
+
+var SecureFileActionFilterValues = struct {
+	None   SecureFileActionFilter
+	Manage SecureFileActionFilter
+	Use    SecureFileActionFilter
+}{
+	None:   SecureFileActionFilter(taskagent.SecureFileActionFilterValues.None),
+	Manage: SecureFileActionFilter(taskagent.SecureFileActionFilterValues.Manage),
+	Use:    SecureFileActionFilter(taskagent.SecureFileActionFilterValues.Use),
+}
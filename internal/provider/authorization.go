@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/pipelinepermissions"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+)
+
+const (
+	secureFileResourceType    = "securefile"
+	variableGroupResourceType = "variablegroup"
+	queueResourceType         = "queue"
+	endpointResourceType      = "endpoint"
+	environmentResourceType   = "environment"
+	repositoryResourceType    = "repository"
+)
+
+var pipelineResourceTypes = []string{
+	secureFileResourceType,
+	variableGroupResourceType,
+	queueResourceType,
+	endpointResourceType,
+	environmentResourceType,
+	repositoryResourceType,
+}
+
+func authorizeProjectReferences(
+	clients *client.Clients, ctx context.Context, projectId *string,
+	definitionResources []build.DefinitionResourceReference,
+) (*[]build.DefinitionResourceReference, error) {
+	return clients.BuildClient.AuthorizeProjectResources(
+		ctx, build.AuthorizeProjectResourcesArgs{
+			Project:   projectId,
+			Resources: &definitionResources,
+		},
+	)
+}
+
+func getProjectResources(
+	clients *client.Clients, ctx context.Context, projectId *string, resourceType string, resourceId string,
+) (*[]build.DefinitionResourceReference, error) {
+	return clients.BuildClient.GetProjectResources(
+		ctx, build.GetProjectResourcesArgs{
+			Project: projectId,
+			Type:    &resourceType,
+			Id:      &resourceId,
+		},
+	)
+}
+
+func isProjectResourceAuthorized(definitionResources *[]build.DefinitionResourceReference, resourceId string) bool {
+	authorized := false
+	if definitionResources != nil {
+		for _, resource := range *definitionResources {
+			if resource.Id != nil && resourceId == *resource.Id && resource.Authorized != nil {
+				authorized = *resource.Authorized
+			}
+		}
+	}
+	return authorized
+}
+
+func getPipelinePermissions(
+	clients *client.Clients, ctx context.Context, projectId *string, resourceType string, resourceId string,
+) (*pipelinepermissions.ResourcePipelinePermissions, error) {
+	return clients.PipelinePermissionsClient.GetPipelinePermissionsForResource(
+		ctx, pipelinepermissions.GetPipelinePermissionsForResourceArgs{
+			Project:      projectId,
+			ResourceType: &resourceType,
+			ResourceId:   &resourceId,
+		},
+	)
+}
+
+func updatePipelinePermissions(
+	clients *client.Clients, ctx context.Context, projectId *string, resourceType string, resourceId string,
+	pipelinePermissions []pipelinepermissions.PipelinePermission,
+) (*pipelinepermissions.ResourcePipelinePermissions, error) {
+	if len(pipelinePermissions) == 0 {
+		return nil, nil
+	}
+
+	return clients.PipelinePermissionsClient.UpdatePipelinePermisionsForResource(
+		ctx, pipelinepermissions.UpdatePipelinePermisionsForResourceArgs{
+			Project:      projectId,
+			ResourceType: &resourceType,
+			ResourceId:   &resourceId,
+			ResourceAuthorization: &pipelinepermissions.ResourcePipelinePermissions{
+				Pipelines: &pipelinePermissions,
+			},
+		},
+	)
+}
+
+// setAllPipelinesAuthorized authorizes all pipelines in the project to use the resource, or removes that authorization,
+// which unlike authorizing project references through the build client is supported for every type of resource.
+func setAllPipelinesAuthorized(
+	clients *client.Clients, ctx context.Context, projectId *string, resourceType string, resourceId string,
+	authorized bool,
+) (*pipelinepermissions.ResourcePipelinePermissions, error) {
+	return clients.PipelinePermissionsClient.UpdatePipelinePermisionsForResource(
+		ctx, pipelinepermissions.UpdatePipelinePermisionsForResourceArgs{
+			Project:      projectId,
+			ResourceType: &resourceType,
+			ResourceId:   &resourceId,
+			ResourceAuthorization: &pipelinepermissions.ResourcePipelinePermissions{
+				AllPipelines: &pipelinepermissions.Permission{
+					Authorized: &authorized,
+				},
+			},
+		},
+	)
+}
+
+func isAllPipelinesAuthorized(pipelinePermissions *pipelinepermissions.ResourcePipelinePermissions) bool {
+	return pipelinePermissions != nil && pipelinePermissions.AllPipelines != nil &&
+		pipelinePermissions.AllPipelines.Authorized != nil && *pipelinePermissions.AllPipelines.Authorized
+}
+
+// expandPipelinePermissions authorizes the pipelines which have been added and revokes the authorization of those which
+// have been removed, leaving the remaining pipelines untouched.
+func expandPipelinePermissions(
+	oldPipelineIds *schema.Set, newPipelineIds *schema.Set,
+) []pipelinepermissions.PipelinePermission {
+	var pipelinePermissions []pipelinepermissions.PipelinePermission
+	for _, pipelineId := range newPipelineIds.Difference(oldPipelineIds).List() {
+		pipelinePermissions = append(pipelinePermissions, newPipelinePermission(pipelineId.(int), true))
+	}
+	for _, pipelineId := range oldPipelineIds.Difference(newPipelineIds).List() {
+		pipelinePermissions = append(pipelinePermissions, newPipelinePermission(pipelineId.(int), false))
+	}
+	return pipelinePermissions
+}
+
+func newPipelinePermission(pipelineId int, authorized bool) pipelinepermissions.PipelinePermission {
+	return pipelinepermissions.PipelinePermission{
+		Id:         &pipelineId,
+		Authorized: &authorized,
+	}
+}
+
+func authorizedPipelineIds(pipelinePermissions *pipelinepermissions.ResourcePipelinePermissions) []int {
+	var pipelineIds []int
+	if pipelinePermissions != nil && pipelinePermissions.Pipelines != nil {
+		for _, pipeline := range *pipelinePermissions.Pipelines {
+			if pipeline.Id != nil && pipeline.Authorized != nil && *pipeline.Authorized {
+				pipelineIds = append(pipelineIds, *pipeline.Id)
+			}
+		}
+	}
+	return pipelineIds
+}
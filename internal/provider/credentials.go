@@ -0,0 +1,387 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+)
+
+// envAuthorityHost overrides the Microsoft Entra ID authority tokens are requested from, such as for sovereign clouds.
+const envAuthorityHost = "AZURE_AUTHORITY_HOST"
+
+// App Service and similar hosts expose the identity endpoint of their managed identity through these environment
+// variables.
+const (
+	envIdentityEndpoint = "IDENTITY_ENDPOINT"
+	envIdentityHeader   = "IDENTITY_HEADER"
+)
+
+// providerConfig is the provider configuration, read from either the SDKv2 or the framework provider.
+type providerConfig struct {
+	OrgServiceUrl             string
+	PersonalAccessToken       string
+	ClientId                  string
+	TenantId                  string
+	ClientSecret              string
+	ClientCertificatePath     string
+	ClientCertificatePassword string
+	UseOidc                   bool
+	OidcToken                 string
+	OidcTokenFilePath         string
+	OidcRequestUrl            string
+	OidcRequestToken          string
+	OidcRequestAzurePipelines bool
+	OidcServiceConnectionId   string
+	UseMsi                    bool
+	MsiEndpoint               string
+	UseCli                    bool
+	CliPath                   string
+	AuthMethods               []string
+}
+
+// The methods the provider can authenticate with Azure DevOps, which auth_methods chooses between.
+const (
+	authMethodPersonalAccessToken = "personal_access_token"
+	authMethodServicePrincipal    = "service_principal"
+	authMethodOidc                = "oidc"
+	authMethodManagedIdentity     = "managed_identity"
+	authMethodAzureCli            = "azure_cli"
+)
+
+// authMethod configures the client to authenticate with one of the methods in the credential chain.
+type authMethod struct {
+	name string
+	// description names the method in diagnostics.
+	description string
+	// configured reports whether the provider configuration asks for the method to be used.
+	configured func(config providerConfig) bool
+	apply      func(config providerConfig, options *client.Options) diag.Diagnostics
+}
+
+// authMethods is the credential chain in its default order of precedence. The Azure CLI is also used when no other
+// credentials are configured and it is installed.
+var authMethods = []authMethod{
+	{
+		name:        authMethodPersonalAccessToken,
+		description: "a personal access token",
+		configured:  func(config providerConfig) bool { return config.PersonalAccessToken != "" },
+		apply: func(config providerConfig, options *client.Options) diag.Diagnostics {
+			options.PersonalAccessToken = config.PersonalAccessToken
+			return nil
+		},
+	},
+	{
+		name:        authMethodServicePrincipal,
+		description: "a service principal",
+		configured: func(config providerConfig) bool {
+			return config.ClientSecret != "" || config.ClientCertificatePath != ""
+		},
+		apply: withCredential(servicePrincipalCredential),
+	},
+	{
+		name:        authMethodOidc,
+		description: "a service principal using OIDC",
+		configured:  func(config providerConfig) bool { return config.UseOidc },
+		apply:       withCredential(oidcCredential),
+	},
+	{
+		name:        authMethodManagedIdentity,
+		description: "a managed identity",
+		configured:  func(config providerConfig) bool { return config.UseMsi },
+		apply: withCredential(
+			func(config providerConfig) (client.TokenCredential, diag.Diagnostics) {
+				return managedIdentityCredential(config), nil
+			},
+		),
+	},
+	{
+		name:        authMethodAzureCli,
+		description: "the Azure CLI",
+		configured:  func(config providerConfig) bool { return config.UseCli },
+		apply:       withCredential(azureCLICredential),
+	},
+}
+
+func withCredential(
+	credential func(config providerConfig) (client.TokenCredential, diag.Diagnostics),
+) func(providerConfig, *client.Options) diag.Diagnostics {
+	return func(config providerConfig, options *client.Options) diag.Diagnostics {
+		var diags diag.Diagnostics
+		options.Credential, diags = credential(config)
+		return diags
+	}
+}
+
+// authCandidate is a method in the credential chain which is configured, along with the client options using it.
+type authCandidate struct {
+	method  authMethod
+	options client.Options
+}
+
+// credentialChain validates the provider configuration and returns the client options for each of the configured
+// authentication methods, in the order they should be attempted. The errors of methods which are configured
+// incorrectly are returned alongside the candidates, so that they can be reported if no other method succeeds.
+func credentialChain(config providerConfig) ([]authCandidate, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if strings.EqualFold(config.OrgServiceUrl, "") {
+		diags = append(
+			diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Organisation service URL not set",
+				Detail:   "The Azure DevOps organisation service URL must be set",
+			},
+		)
+	}
+
+	methods, methodDiags := selectAuthMethods(config.AuthMethods)
+	diags = append(diags, methodDiags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var candidates []authCandidate
+	var configured bool
+	for _, method := range methods {
+		if !method.configured(config) {
+			continue
+		}
+		configured = true
+
+		candidate := authCandidate{method: method, options: client.Options{OrganisationUrl: config.OrgServiceUrl}}
+		if methodDiags := method.apply(config, &candidate.options); methodDiags.HasError() {
+			diags = append(diags, methodDiags...)
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	if configured {
+		return candidates, diags
+	}
+
+	// A client or tenant ID on its own does not configure any method, such as when ARM_CLIENT_ID is set in an
+	// environment for another tool, so the Azure CLI is still used. The tenant ID selects the tenant it requests access
+	// tokens from.
+	for _, method := range methods {
+		if method.name != authMethodAzureCli {
+			continue
+		}
+		candidate := authCandidate{method: method, options: client.Options{OrganisationUrl: config.OrgServiceUrl}}
+		if methodDiags := method.apply(config, &candidate.options); !methodDiags.HasError() {
+			return []authCandidate{candidate}, diags
+		}
+	}
+
+	return nil, append(
+		diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "No credentials configured",
+			Detail: "Either " + argPersonalAccessToken + " must be set, " + argClientId + " and " +
+				argTenantId + " must be set along with one of " + argClientSecret + ", " +
+				argClientCertificatePath + " or " + argUseOidc + ", one of " + argUseMsi + " or " + argUseCli +
+				" must be enabled, or the Azure CLI must be installed and logged in with `az login`.",
+		},
+	)
+}
+
+// selectAuthMethods returns the named authentication methods in the given order, or every method in the default order
+// when none are named.
+func selectAuthMethods(names []string) ([]authMethod, diag.Diagnostics) {
+	if len(names) == 0 {
+		return authMethods, nil
+	}
+
+	var methods []authMethod
+	var diags diag.Diagnostics
+	for _, name := range names {
+		index := slices.IndexFunc(authMethods, func(method authMethod) bool { return method.name == name })
+		if index < 0 {
+			diags = append(
+				diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Invalid authentication method",
+					Detail:   fmt.Sprintf("%q is not one of %s.", name, strings.Join(authMethodNames(), ", ")),
+				},
+			)
+			continue
+		}
+		methods = append(methods, authMethods[index])
+	}
+
+	return methods, diags
+}
+
+func authMethodNames() []string {
+	names := make([]string, 0, len(authMethods))
+	for _, method := range authMethods {
+		names = append(names, method.name)
+	}
+	return names
+}
+
+func servicePrincipalCredential(config providerConfig) (client.TokenCredential, diag.Diagnostics) {
+	diags := requireServicePrincipalIds(config, "a service principal")
+
+	if config.ClientSecret != "" && config.ClientCertificatePath != "" {
+		diags = append(
+			diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Conflicting service principal credentials",
+				Detail:   "Only one of " + argClientSecret + " and " + argClientCertificatePath + " may be set.",
+			},
+		)
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	authorityHost := os.Getenv(envAuthorityHost)
+
+	if config.ClientSecret != "" {
+		return client.NewClientSecretCredential(
+			authorityHost, config.TenantId, config.ClientId, config.ClientSecret,
+		), diags
+	}
+
+	certificate, err := os.ReadFile(config.ClientCertificatePath)
+	if err != nil {
+		return nil, append(
+			diags, diag.Diagnostic{Severity: diag.Error, Summary: "Error reading client certificate", Detail: err.Error()},
+		)
+	}
+
+	credential, err := client.NewClientCertificateCredential(
+		authorityHost, config.TenantId, config.ClientId, certificate, config.ClientCertificatePassword,
+	)
+	if err != nil {
+		return nil, append(
+			diags, diag.Diagnostic{Severity: diag.Error, Summary: "Invalid client certificate", Detail: err.Error()},
+		)
+	}
+
+	return credential, diags
+}
+
+// withOidcRequestFromEnv returns the configuration with the URL and bearer token to request a federated OIDC token
+// with read from the first pair of environment variables in oidcRequestEnvs whose URL is set, when neither is
+// configured.
+func (c providerConfig) withOidcRequestFromEnv() providerConfig {
+	if c.OidcRequestUrl != "" || c.OidcRequestToken != "" {
+		return c
+	}
+	for _, env := range oidcRequestEnvs {
+		if requestUrl := os.Getenv(env.url); requestUrl != "" {
+			c.OidcRequestUrl = requestUrl
+			c.OidcRequestToken = os.Getenv(env.token)
+			c.OidcRequestAzurePipelines = env.azurePipelines
+			break
+		}
+	}
+	return c
+}
+
+// oidcCredential authenticates a service principal by exchanging a federated OIDC token, which is either given or
+// requested from the CI system running Terraform, for an access token.
+func oidcCredential(config providerConfig) (client.TokenCredential, diag.Diagnostics) {
+	diags := requireServicePrincipalIds(config, "OIDC")
+
+	var assertion client.ClientAssertion
+	switch {
+	case config.OidcToken != "":
+		assertion = client.OIDCTokenAssertion(config.OidcToken)
+	case config.OidcTokenFilePath != "":
+		assertion = client.OIDCTokenFileAssertion(config.OidcTokenFilePath)
+	case config.OidcRequestUrl != "" && config.OidcRequestToken != "":
+		switch {
+		case config.OidcServiceConnectionId != "":
+			assertion = client.AzurePipelinesOIDCAssertion(
+				nil, config.OidcRequestUrl, config.OidcRequestToken, config.OidcServiceConnectionId,
+			)
+		case config.OidcRequestAzurePipelines:
+			// Azure Pipelines only issues tokens for a service connection, so it must not be asked as GitHub Actions is
+			diags = append(
+				diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Incomplete OIDC configuration",
+					Detail: argOidcServiceConnectionId + " must be set to authenticate with OIDC in Azure Pipelines, " +
+						"as the federated OIDC token is requested from SYSTEM_OIDCREQUESTURI.",
+				},
+			)
+		default:
+			assertion = client.GitHubActionsOIDCAssertion(nil, config.OidcRequestUrl, config.OidcRequestToken)
+		}
+	default:
+		diags = append(
+			diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Incomplete OIDC configuration",
+				Detail: "One of " + argOidcToken + " or " + argOidcTokenFilePath + ", or both " + argOidcRequestUrl +
+					" and " + argOidcRequestToken + ", must be set to authenticate with OIDC.",
+			},
+		)
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return client.NewClientAssertionCredential(
+		os.Getenv(envAuthorityHost), config.TenantId, config.ClientId, assertion,
+	), diags
+}
+
+// managedIdentityCredential authenticates with the managed identity of the Azure resource Terraform is running on,
+// using the identity endpoint of App Service and similar hosts when running on one and no endpoint is configured.
+func managedIdentityCredential(config providerConfig) client.TokenCredential {
+	endpoint, identityHeader := config.MsiEndpoint, ""
+	if endpoint == "" && os.Getenv(envIdentityEndpoint) != "" && os.Getenv(envIdentityHeader) != "" {
+		endpoint, identityHeader = os.Getenv(envIdentityEndpoint), os.Getenv(envIdentityHeader)
+	}
+
+	return client.NewManagedIdentityCredential(endpoint, identityHeader, config.ClientId)
+}
+
+// azureCLICredential authenticates with the account the Azure CLI is logged in with, in the tenant given by tenant_id
+// when it is set.
+func azureCLICredential(config providerConfig) (client.TokenCredential, diag.Diagnostics) {
+	command := config.CliPath
+	if command == "" {
+		command = client.DefaultAzureCLICommand
+	}
+
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, diag.Diagnostics{
+			diag.Diagnostic{Severity: diag.Error, Summary: "Azure CLI not found", Detail: err.Error()},
+		}
+	}
+
+	return client.NewAzureCLICredential(path, config.TenantId), nil
+}
+
+// requireServicePrincipalIds reports the IDs identifying the service principal which are not set.
+func requireServicePrincipalIds(config providerConfig, method string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	required := []struct{ arg, value string }{{argClientId, config.ClientId}, {argTenantId, config.TenantId}}
+	for _, r := range required {
+		if r.value == "" {
+			diags = append(
+				diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Incomplete service principal configuration",
+					Detail:   r.arg + " must be set to authenticate with " + method + ".",
+				},
+			)
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,475 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+)
+
+func TestCredentialChain(t *testing.T) {
+	invalidCertificatePath := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidCertificatePath, []byte("not a certificate"), 0600))
+	cliPath := filepath.Join(t.TempDir(), "az")
+	require.NoError(t, os.WriteFile(cliPath, []byte("#!/bin/sh\n"), 0700))
+	missingCliPath := filepath.Join(t.TempDir(), "missing-az")
+
+	test := func(
+		config providerConfig, expectedPersonalAccessToken string, expectedCredential client.TokenCredential,
+		expectedSummaries []string,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			candidates, diags := credentialChain(config)
+
+			summaries := make([]string, 0, len(diags))
+			for _, diagnostic := range diags {
+				summaries = append(summaries, diagnostic.Summary)
+			}
+			require.Equal(t, expectedSummaries, summaries)
+			if expectedPersonalAccessToken == "" && expectedCredential == nil {
+				require.Empty(t, candidates)
+				return
+			}
+
+			require.NotEmpty(t, candidates)
+			options := candidates[0].options
+			require.Equal(t, config.OrgServiceUrl, options.OrganisationUrl)
+			require.Equal(t, expectedPersonalAccessToken, options.PersonalAccessToken)
+			if expectedCredential != nil {
+				require.IsType(t, expectedCredential, options.Credential)
+			} else {
+				require.Nil(t, options.Credential)
+			}
+		}
+	}
+
+	servicePrincipal := &client.ServicePrincipalCredential{}
+	managedIdentity := &client.ManagedIdentityCredential{}
+	azureCli := &client.AzureCLICredential{}
+
+	orgServiceUrl := "https://dev.azure.com/example"
+	clientId := "00000000-0000-0000-0000-000000000001"
+	tenantId := "00000000-0000-0000-0000-000000000002"
+
+	t.Run(
+		"personal_access_token", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, PersonalAccessToken: "some-pat"}, "some-pat", nil,
+			[]string{},
+		),
+	)
+	t.Run(
+		"personal_access_token_precedence", test(
+			providerConfig{
+				OrgServiceUrl:       orgServiceUrl,
+				PersonalAccessToken: "some-pat",
+				ClientId:            clientId,
+				TenantId:            tenantId,
+				ClientSecret:        "secret",
+			}, "some-pat", nil, []string{},
+		),
+	)
+	t.Run(
+		"client_secret", test(
+			providerConfig{
+				OrgServiceUrl: orgServiceUrl,
+				ClientId:      clientId,
+				TenantId:      tenantId,
+				ClientSecret:  "secret",
+			}, "", servicePrincipal, []string{},
+		),
+	)
+	t.Run(
+		"client_certificate_missing", test(
+			providerConfig{
+				OrgServiceUrl:         orgServiceUrl,
+				ClientId:              clientId,
+				TenantId:              tenantId,
+				ClientCertificatePath: filepath.Join(t.TempDir(), "missing.pem"),
+			}, "", nil, []string{"Error reading client certificate"},
+		),
+	)
+	t.Run(
+		"client_certificate_invalid", test(
+			providerConfig{
+				OrgServiceUrl:         orgServiceUrl,
+				ClientId:              clientId,
+				TenantId:              tenantId,
+				ClientCertificatePath: invalidCertificatePath,
+			}, "", nil, []string{"Invalid client certificate"},
+		),
+	)
+	t.Run(
+		"conflicting", test(
+			providerConfig{
+				OrgServiceUrl:         orgServiceUrl,
+				ClientId:              clientId,
+				TenantId:              tenantId,
+				ClientSecret:          "secret",
+				ClientCertificatePath: invalidCertificatePath,
+			}, "", nil, []string{"Conflicting service principal credentials"},
+		),
+	)
+	t.Run(
+		"incomplete", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, ClientSecret: "secret"}, "", nil, []string{
+				"Incomplete service principal configuration",
+				"Incomplete service principal configuration",
+			},
+		),
+	)
+	t.Run(
+		"client_id_only", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, ClientId: clientId, TenantId: tenantId, CliPath: cliPath},
+			"", azureCli, []string{},
+		),
+	)
+	t.Run(
+		"oidc_token", test(
+			providerConfig{
+				OrgServiceUrl: orgServiceUrl,
+				ClientId:      clientId,
+				TenantId:      tenantId,
+				UseOidc:       true,
+				OidcToken:     "federated-token",
+			}, "", servicePrincipal, []string{},
+		),
+	)
+	t.Run(
+		"oidc_token_file_path", test(
+			providerConfig{
+				OrgServiceUrl:     orgServiceUrl,
+				ClientId:          clientId,
+				TenantId:          tenantId,
+				UseOidc:           true,
+				OidcTokenFilePath: "/var/run/secrets/token",
+			}, "", servicePrincipal, []string{},
+		),
+	)
+	t.Run(
+		"oidc_azure_pipelines", test(
+			providerConfig{
+				OrgServiceUrl:           orgServiceUrl,
+				ClientId:                clientId,
+				TenantId:                tenantId,
+				UseOidc:                 true,
+				OidcRequestUrl:          "https://dev.azure.com/example/00000000-0000-0000-0000-000000000003/_apis",
+				OidcRequestToken:        "system-access-token",
+				OidcServiceConnectionId: "00000000-0000-0000-0000-000000000004",
+			}, "", servicePrincipal, []string{},
+		),
+	)
+	t.Run(
+		"oidc_azure_pipelines_service_connection_missing", test(
+			providerConfig{
+				OrgServiceUrl:             orgServiceUrl,
+				ClientId:                  clientId,
+				TenantId:                  tenantId,
+				UseOidc:                   true,
+				OidcRequestUrl:            "https://dev.azure.com/example/00000000-0000-0000-0000-000000000003/_apis",
+				OidcRequestToken:          "system-access-token",
+				OidcRequestAzurePipelines: true,
+			}, "", nil, []string{"Incomplete OIDC configuration"},
+		),
+	)
+	t.Run(
+		"oidc_client_secret_precedence", test(
+			providerConfig{
+				OrgServiceUrl: orgServiceUrl,
+				ClientId:      clientId,
+				TenantId:      tenantId,
+				ClientSecret:  "secret",
+				UseOidc:       true,
+			}, "", servicePrincipal, []string{"Incomplete OIDC configuration"},
+		),
+	)
+	t.Run(
+		"oidc_incomplete", test(
+			providerConfig{
+				OrgServiceUrl:  orgServiceUrl,
+				TenantId:       tenantId,
+				UseOidc:        true,
+				OidcRequestUrl: "https://example.com/token",
+			}, "", nil, []string{"Incomplete service principal configuration", "Incomplete OIDC configuration"},
+		),
+	)
+	t.Run(
+		"msi", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, UseMsi: true}, "", managedIdentity, []string{},
+		),
+	)
+	t.Run(
+		"msi_user_assigned", test(
+			providerConfig{
+				OrgServiceUrl: orgServiceUrl,
+				ClientId:      clientId,
+				UseMsi:        true,
+				MsiEndpoint:   "http://localhost:50342/oauth2/token",
+			}, "", managedIdentity, []string{},
+		),
+	)
+	t.Run(
+		"cli", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, TenantId: tenantId, UseCli: true, CliPath: cliPath}, "",
+			azureCli, []string{},
+		),
+	)
+	t.Run(
+		"cli_missing", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, UseCli: true, CliPath: missingCliPath}, "", nil,
+			[]string{"Azure CLI not found"},
+		),
+	)
+	t.Run(
+		"cli_fallback", test(providerConfig{OrgServiceUrl: orgServiceUrl, CliPath: cliPath}, "", azureCli, []string{}),
+	)
+	t.Run("cli_fallback_tenant", func(t *testing.T) {
+		candidates, diags := credentialChain(
+			providerConfig{OrgServiceUrl: orgServiceUrl, TenantId: tenantId, CliPath: cliPath},
+		)
+		require.Empty(t, diags)
+		require.Len(t, candidates, 1)
+		require.Equal(t, authMethodAzureCli, candidates[0].method.name)
+		require.Equal(t, client.NewAzureCLICredential(cliPath, tenantId), candidates[0].options.Credential)
+	})
+	t.Run(
+		"cli_fallback_client_id", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, ClientId: clientId, CliPath: cliPath}, "", azureCli,
+			[]string{},
+		),
+	)
+	t.Run(
+		"none", test(
+			providerConfig{OrgServiceUrl: orgServiceUrl, CliPath: missingCliPath}, "", nil,
+			[]string{"No credentials configured"},
+		),
+	)
+	t.Run(
+		"missing_org_service_url", test(
+			providerConfig{PersonalAccessToken: "some-pat"}, "", nil, []string{"Organisation service URL not set"},
+		),
+	)
+}
+
+func TestOidcRequestFromEnv(t *testing.T) {
+	t.Setenv("ARM_OIDC_REQUEST_URL", "")
+	t.Setenv("ARM_OIDC_REQUEST_TOKEN", "arm-token")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+	t.Setenv("SYSTEM_OIDCREQUESTURI", "https://dev.azure.com/example/_apis")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "system-access-token")
+
+	config := providerConfig{}.withOidcRequestFromEnv()
+	require.Equal(t, "https://dev.azure.com/example/_apis", config.OidcRequestUrl)
+	require.Equal(t, "system-access-token", config.OidcRequestToken)
+	require.True(t, config.OidcRequestAzurePipelines)
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "https://example.com/token")
+	config = providerConfig{}.withOidcRequestFromEnv()
+	require.Equal(t, "https://example.com/token", config.OidcRequestUrl)
+	require.Empty(t, config.OidcRequestToken)
+	require.False(t, config.OidcRequestAzurePipelines)
+
+	config = providerConfig{OidcRequestToken: "configured-token"}.withOidcRequestFromEnv()
+	require.Empty(t, config.OidcRequestUrl)
+	require.Equal(t, "configured-token", config.OidcRequestToken)
+}
+
+func TestCredentialChainOrder(t *testing.T) {
+	cliPath := filepath.Join(t.TempDir(), "az")
+	require.NoError(t, os.WriteFile(cliPath, []byte("#!/bin/sh\n"), 0700))
+
+	test := func(authMethods []string, expectedMethods []string, expectedSummaries []string) func(*testing.T) {
+		return func(t *testing.T) {
+			candidates, diags := credentialChain(
+				providerConfig{
+					OrgServiceUrl:       "https://dev.azure.com/example",
+					PersonalAccessToken: "some-pat",
+					ClientId:            "00000000-0000-0000-0000-000000000001",
+					TenantId:            "00000000-0000-0000-0000-000000000002",
+					ClientSecret:        "secret",
+					UseMsi:              true,
+					UseCli:              true,
+					CliPath:             cliPath,
+					AuthMethods:         authMethods,
+				},
+			)
+
+			summaries := make([]string, 0, len(diags))
+			for _, diagnostic := range diags {
+				summaries = append(summaries, diagnostic.Summary)
+			}
+			require.Equal(t, expectedSummaries, summaries)
+
+			methods := make([]string, 0, len(candidates))
+			for _, candidate := range candidates {
+				methods = append(methods, candidate.method.name)
+			}
+			require.Equal(t, expectedMethods, methods)
+		}
+	}
+
+	t.Run(
+		"default", test(
+			nil, []string{
+				authMethodPersonalAccessToken, authMethodServicePrincipal, authMethodManagedIdentity,
+				authMethodAzureCli,
+			}, []string{},
+		),
+	)
+	t.Run(
+		"configured", test(
+			[]string{authMethodAzureCli, authMethodOidc, authMethodManagedIdentity},
+			[]string{authMethodAzureCli, authMethodManagedIdentity}, []string{},
+		),
+	)
+	t.Run(
+		"invalid", test(
+			[]string{authMethodAzureCli, "pat"}, []string{}, []string{"Invalid authentication method"},
+		),
+	)
+}
+
+// newTestOrganisation stands in for an Azure DevOps organisation which requires requests to present the given
+// authorization.
+func newTestOrganisation(t *testing.T, authorization string) *httptest.Server {
+	resourceAreasLocationId := uuid.MustParse("e81700f7-3be2-46de-8624-2eb35882fcaa")
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != authorization {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.Header().Set("Content-Type", azuredevops.MediaTypeApplicationJson)
+				switch {
+				case r.Method == http.MethodOptions && r.URL.Path == "/_apis":
+					area, resourceName, routeTemplate := "Location", "ResourceAreas", "_apis/{resource}/{areaId}"
+					minVersion, maxVersion, releasedVersion, resourceVersion := "3.2", "7.1", "0.0", 1
+					_ = json.NewEncoder(w).Encode(
+						map[string]interface{}{
+							"count": 1,
+							"value": []azuredevops.ApiResourceLocation{
+								{
+									Id:              &resourceAreasLocationId,
+									Area:            &area,
+									ResourceName:    &resourceName,
+									RouteTemplate:   &routeTemplate,
+									ResourceVersion: &resourceVersion,
+									MinVersion:      &minVersion,
+									MaxVersion:      &maxVersion,
+									ReleasedVersion: &releasedVersion,
+								},
+							},
+						},
+					)
+				case r.Method == http.MethodGet && r.URL.Path == "/_apis/ResourceAreas":
+					// Respond as an on-prem server would, so everything is served from the test server
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"count": 0, "value": []interface{}{}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewClients(t *testing.T) {
+	organisation := newTestOrganisation(t, "Bearer cli-token")
+
+	tokenEndpoint := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(
+					map[string]interface{}{"error": "invalid_client", "error_description": "Invalid client secret."},
+				)
+			},
+		),
+	)
+	t.Cleanup(tokenEndpoint.Close)
+	t.Setenv(envAuthorityHost, tokenEndpoint.URL)
+
+	cliPath := filepath.Join(t.TempDir(), "az")
+	require.NoError(
+		t, os.WriteFile(
+			cliPath, []byte("#!/bin/sh\necho '{\"accessToken\": \"cli-token\", \"expires_on\": 4102444800}'\n"), 0700,
+		),
+	)
+	failingCliPath := filepath.Join(t.TempDir(), "az")
+	require.NoError(
+		t, os.WriteFile(failingCliPath, []byte("#!/bin/sh\necho 'ERROR: Please run az login.' >&2\nexit 1\n"), 0700),
+	)
+
+	test := func(
+		config providerConfig, expectedClients bool, expectedDiags []diag.Diagnostic,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			clients, diags := newClients(context.Background(), config, "dev", "1.0.0")
+
+			require.Equal(t, expectedClients, clients != nil)
+			require.Len(t, diags, len(expectedDiags))
+			for i, expected := range expectedDiags {
+				require.Equal(t, expected.Severity, diags[i].Severity)
+				require.Equal(t, expected.Summary, diags[i].Summary)
+				require.Contains(t, diags[i].Detail, expected.Detail)
+			}
+		}
+	}
+
+	servicePrincipal := providerConfig{
+		OrgServiceUrl: organisation.URL,
+		ClientId:      "00000000-0000-0000-0000-000000000001",
+		TenantId:      "00000000-0000-0000-0000-000000000002",
+		ClientSecret:  "secret",
+		UseCli:        true,
+	}
+
+	succeeding := servicePrincipal
+	succeeding.CliPath = cliPath
+	t.Run(
+		"fallback", test(
+			succeeding, true, []diag.Diagnostic{
+				{
+					Severity: diag.Warning,
+					Summary:  "Error initialising Azure DevOps clients with a service principal",
+					Detail:   "invalid_client: Invalid client secret.",
+				},
+				{Severity: diag.Warning, Summary: "Authenticated with the Azure CLI", Detail: `"azure_cli"`},
+			},
+		),
+	)
+
+	failing := servicePrincipal
+	failing.CliPath = failingCliPath
+	t.Run(
+		"all_failed", test(
+			failing, false, []diag.Diagnostic{
+				{
+					Severity: diag.Error,
+					Summary:  "Error initialising Azure DevOps clients with a service principal",
+					Detail:   "invalid_client: Invalid client secret.",
+				},
+				{
+					Severity: diag.Error,
+					Summary:  "Error initialising Azure DevOps clients with the Azure CLI",
+					Detail:   "ERROR: Please run az login.",
+				},
+			},
+		),
+	)
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+)
+
+func dataSourceSecureFile() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to access information about an existing secure file within Azure DevOps.",
+
+		ReadContext: dataSourceSecureFileRead,
+
+		Schema: map[string]*schema.Schema{
+			sfProjectId: {
+				Description:  "The ID of the Azure DevOps project the secure file belongs to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			"id": {
+				Description:  "The ID of the secure file.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", sfName},
+				ValidateFunc: validation.IsUUID,
+			},
+			sfName: {
+				Description:  "The name of the secure file.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", sfName},
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			sfAllowAccess: {
+				Description: "Whether all pipelines are allowed access to the secure file.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			sfPipelineIds: {
+				Description: "The IDs of the pipelines individually authorized to use the secure file.",
+				Type:        schema.TypeSet,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Computed:    true,
+			},
+			sfProperties: {
+				Description: "Properties assigned to the secure file.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			sfCreatedBy: {
+				Description: "The display name of the identity that created the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfCreatedByDescriptor: {
+				Description: "The descriptor of the identity that created the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfCreatedOn: {
+				Description: "The timestamp at which the secure file was created.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfModifiedBy: {
+				Description: "The display name of the identity that last modified the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfModifiedByDescriptor: {
+				Description: "The descriptor of the identity that last modified the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfModifiedOn: {
+				Description: "The timestamp at which the secure file was last modified.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceSecureFileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId := d.Get(sfProjectId).(string)
+
+	var secureFile *taskagent.SecureFile
+	if secureFileId, ok := d.GetOk("id"); ok {
+		id := uuid.MustParse(secureFileId.(string))
+
+		var err error
+		secureFile, err = clients.TaskAgentClient.GetSecureFile(
+			ctx, taskagent.GetSecureFileArgs{
+				Project:      &projectId,
+				SecureFileId: &id,
+			},
+		)
+		if err != nil {
+			return diag.Errorf(
+				"Error looking up secure file given ID (%v) and project ID (%v): %v", id, projectId, err,
+			)
+		}
+	} else {
+		name := d.Get(sfName).(string)
+
+		var err error
+		secureFile, err = getSecureFileByName(clients, ctx, &projectId, name)
+		if err != nil {
+			return diag.Errorf(
+				"Error looking up secure file given name (%v) and project ID (%v): %v", name, projectId, err,
+			)
+		}
+		if secureFile == nil {
+			return diag.Errorf("Secure file with name (%v) not found in project ID (%v)", name, projectId)
+		}
+	}
+
+	flattenSecureFile(d, secureFile, &projectId)
+	flattenSecureFileMetadata(d, secureFile)
+
+	projectResources, err := getProjectResources(
+		clients, ctx, &projectId, secureFileResourceType, secureFile.Id.String(),
+	)
+	if err != nil {
+		return diag.Errorf(
+			"Error looking up project resources given ID (%v) and project ID (%v): %v", secureFile.Id, projectId, err,
+		)
+	}
+
+	flattenAllowAccess(d, projectResources)
+
+	pipelinePermissions, err := getPipelinePermissions(
+		clients, ctx, &projectId, secureFileResourceType, secureFile.Id.String(),
+	)
+	if err != nil {
+		return diag.Errorf(
+			"Error looking up pipeline permissions given ID (%v) and project ID (%v): %v", secureFile.Id, projectId,
+			err,
+		)
+	}
+
+	flattenPipelinePermissions(d, pipelinePermissions)
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+func TestAccDataSourceSecureFile(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccDataSourceSecureFileConfig(projectId, fileName),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttrPair(
+							"data.azdoext_secure_file.by_id", "name", "azdoext_secure_file.foo", "name",
+						),
+						resource.TestCheckResourceAttr("data.azdoext_secure_file.by_id", "allow_access", "true"),
+						resource.TestCheckResourceAttr("data.azdoext_secure_file.by_id", "properties.foo", "bar"),
+						resource.TestCheckResourceAttr("data.azdoext_secure_file.by_id", "authorized_pipeline_ids.#", "0"),
+						resource.TestCheckResourceAttrSet("data.azdoext_secure_file.by_id", "created_on"),
+						resource.TestCheckResourceAttrSet("data.azdoext_secure_file.by_id", "created_by_descriptor"),
+						resource.TestCheckResourceAttrPair(
+							"data.azdoext_secure_file.by_name", "id", "azdoext_secure_file.foo", "id",
+						),
+						resource.TestCheckResourceAttr("data.azdoext_secure_file.by_name", "allow_access", "true"),
+						resource.TestCheckResourceAttr("data.azdoext_secure_file.by_name", "properties.foo", "bar"),
+						resource.TestCheckResourceAttrSet("data.azdoext_secure_file.by_name", "modified_on"),
+					),
+				},
+			},
+		},
+	)
+}
+
+func TestGetSecureFileByName(t *testing.T) {
+	secureFileId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	commaSecureFileId := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{
+			secureFiles: []taskagent.SecureFile{
+				{Id: &secureFileId, Name: utils.NewString("signing.p12")},
+				{Id: &commaSecureFileId, Name: utils.NewString("signing,dev.p12")},
+			},
+		},
+	}
+
+	test := func(name string, expectedId *uuid.UUID) func(*testing.T) {
+		return func(t *testing.T) {
+			secureFile, err := getSecureFileByName(clients, context.Background(), utils.NewString("project"), name)
+			require.NoError(t, err)
+			if expectedId == nil {
+				require.Nil(t, secureFile)
+				return
+			}
+			require.Equal(t, *expectedId, *secureFile.Id)
+		}
+	}
+
+	t.Run("same_case", test("signing.p12", &secureFileId))
+	t.Run("different_case", test("SIGNING.P12", &secureFileId))
+	t.Run("not_found", test("profile.mobileprovision", nil))
+	// Names containing a comma are split by Azure DevOps when looking them up by name
+	t.Run("comma", test("Signing,Dev.p12", &commaSecureFileId))
+	t.Run("comma_not_found", test("signing,prod.p12", nil))
+}
+
+func testAccDataSourceSecureFileConfig(projectId string, fileName string) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name = %q
+  content = "Hello World"
+  allow_access = true
+  properties = {
+    foo = "bar"
+  }
+}
+
+data "azdoext_secure_file" "by_id" {
+  project_id = azdoext_secure_file.foo.project_id
+  id = azdoext_secure_file.foo.id
+}
+
+data "azdoext_secure_file" "by_name" {
+  project_id = azdoext_secure_file.foo.project_id
+  name = azdoext_secure_file.foo.name
+}
+`, projectId, fileName,
+	)
+}
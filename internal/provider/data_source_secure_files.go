@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+)
+
+const (
+	sfsNamePrefix   = "name_prefix"
+	sfsNameRegex    = "name_regex"
+	sfsActionFilter = "action_filter"
+	sfsSecureFiles  = "secure_files"
+)
+
+func dataSourceSecureFiles() *schema.Resource {
+	return &schema.Resource{
+		Description: "Use this data source to list the secure files within an Azure DevOps project.",
+
+		ReadContext: dataSourceSecureFilesRead,
+
+		Schema: map[string]*schema.Schema{
+			sfProjectId: {
+				Description:  "The ID of the Azure DevOps project to list the secure files of.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			sfsNamePrefix: {
+				Description:  "Only include secure files whose name starts with this prefix, ignoring case as Azure DevOps does.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			sfsNameRegex: {
+				Description:  "Only include secure files whose name matches this regular expression.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			sfProperties: {
+				Description: "Only include secure files which have all of these properties assigned.",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+			sfsActionFilter: {
+				Description: "Only include secure files the authenticated identity is allowed to perform this action on. " +
+					"Must be one of `use` or `manage`.",
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						string(taskagent.SecureFileActionFilterValues.Use),
+						string(taskagent.SecureFileActionFilterValues.Manage),
+					}, true,
+				),
+			},
+			sfsSecureFiles: {
+				Description: "The secure files matching the filters, ordered by name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "The ID of the secure file.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sfName: {
+							Description: "The name of the secure file.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						sfProperties: {
+							Description: "Properties assigned to the secure file.",
+							Type:        schema.TypeMap,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSecureFilesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId := d.Get(sfProjectId).(string)
+	namePrefix := d.Get(sfsNamePrefix).(string)
+
+	args := taskagent.GetSecureFilesArgs{
+		Project: &projectId,
+	}
+	if namePrefix != "" {
+		namePattern := namePrefix + "*"
+		args.NamePattern = &namePattern
+	}
+	if actionFilter, ok := d.GetOk(sfsActionFilter); ok {
+		filter := taskagent.SecureFileActionFilter(strings.ToLower(actionFilter.(string)))
+		args.ActionFilter = &filter
+	}
+
+	secureFiles, err := clients.TaskAgentClient.GetSecureFiles(ctx, args)
+	if err != nil {
+		return diag.Errorf("Error listing secure files given project ID (%v): %v", projectId, err)
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk(sfsNameRegex); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	properties := map[string]string{}
+	for k, v := range d.Get(sfProperties).(map[string]interface{}) {
+		properties[k] = v.(string)
+	}
+
+	var matching []taskagent.SecureFile
+	for _, secureFile := range *secureFiles {
+		if secureFileMatches(secureFile, namePrefix, nameRegex, properties) {
+			matching = append(matching, secureFile)
+		}
+	}
+
+	sort.Slice(
+		matching, func(i, j int) bool {
+			return *matching[i].Name < *matching[j].Name
+		},
+	)
+
+	d.SetId(projectId)
+	_ = d.Set(sfsSecureFiles, flattenSecureFiles(matching))
+
+	return nil
+}
+
+func secureFileMatches(
+	secureFile taskagent.SecureFile, namePrefix string, nameRegex *regexp.Regexp, properties map[string]string,
+) bool {
+	if secureFile.Id == nil || secureFile.Name == nil {
+		return false
+	}
+	// Secure file names are case-insensitive, as is the name pattern used to list them
+	if !strings.HasPrefix(strings.ToLower(*secureFile.Name), strings.ToLower(namePrefix)) {
+		return false
+	}
+	if nameRegex != nil && !nameRegex.MatchString(*secureFile.Name) {
+		return false
+	}
+	for k, v := range properties {
+		if secureFile.Properties == nil {
+			return false
+		}
+		if actual, ok := (*secureFile.Properties)[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+func flattenSecureFiles(secureFiles []taskagent.SecureFile) []interface{} {
+	flattened := make([]interface{}, len(secureFiles))
+	for i, secureFile := range secureFiles {
+		properties := map[string]string{}
+		if secureFile.Properties != nil {
+			properties = *secureFile.Properties
+		}
+		flattened[i] = map[string]interface{}{
+			"id":         secureFile.Id.String(),
+			sfName:       *secureFile.Name,
+			sfProperties: properties,
+		}
+	}
+	return flattened
+}
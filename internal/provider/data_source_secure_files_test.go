@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+func TestAccDataSourceSecureFiles(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	prefix := id.String()
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccDataSourceSecureFilesConfig(projectId, prefix),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("data.azdoext_secure_files.all", "secure_files.#", "2"),
+						resource.TestCheckResourceAttr(
+							"data.azdoext_secure_files.all", "secure_files.0.name", prefix+"-a.txt",
+						),
+						resource.TestCheckResourceAttr(
+							"data.azdoext_secure_files.all", "secure_files.1.name", prefix+"-b.txt",
+						),
+						resource.TestCheckResourceAttr("data.azdoext_secure_files.filtered", "secure_files.#", "1"),
+						resource.TestCheckResourceAttrPair(
+							"data.azdoext_secure_files.filtered", "secure_files.0.id", "azdoext_secure_file.b", "id",
+						),
+						resource.TestCheckResourceAttr(
+							"data.azdoext_secure_files.filtered", "secure_files.0.properties.team", "b",
+						),
+					),
+				},
+			},
+		},
+	)
+}
+
+// fakeSecureFilesClient records the arguments it is asked to list secure files with.
+type fakeSecureFilesClient struct {
+	taskagent.Client
+	args        taskagent.GetSecureFilesArgs
+	secureFiles []taskagent.SecureFile
+}
+
+func (c *fakeSecureFilesClient) GetSecureFiles(
+	_ context.Context, args taskagent.GetSecureFilesArgs,
+) (*[]taskagent.SecureFile, error) {
+	c.args = args
+	return &c.secureFiles, nil
+}
+
+func TestDataSourceSecureFilesRead(t *testing.T) {
+	projectId := "00000000-0000-0000-0000-0000000000ff"
+	newSecureFile := func(id string, name string, team string) taskagent.SecureFile {
+		secureFileId := uuid.MustParse(id)
+		return taskagent.SecureFile{
+			Id: &secureFileId, Name: utils.NewString(name), Properties: &map[string]string{"team": team},
+		}
+	}
+	taskAgentClient := &fakeSecureFilesClient{
+		secureFiles: []taskagent.SecureFile{
+			newSecureFile("00000000-0000-0000-0000-000000000001", "signing-b.p12", "mobile"),
+			newSecureFile("00000000-0000-0000-0000-000000000002", "signing-a.p12", "mobile"),
+			newSecureFile("00000000-0000-0000-0000-000000000003", "signing-c.p12", "web"),
+		},
+	}
+
+	d := schema.TestResourceDataRaw(
+		t, dataSourceSecureFiles().Schema, map[string]interface{}{
+			sfProjectId:     projectId,
+			sfsNamePrefix:   "signing-",
+			sfsActionFilter: "Use",
+			sfProperties:    map[string]interface{}{"team": "mobile"},
+		},
+	)
+	diags := dataSourceSecureFilesRead(
+		context.Background(), d, &client.Clients{TaskAgentClient: taskAgentClient},
+	)
+	require.False(t, diags.HasError())
+
+	// The action filter is matched ignoring case, but must be sent as Azure DevOps expects it
+	require.Equal(t, projectId, *taskAgentClient.args.Project)
+	require.Equal(t, "signing-*", *taskAgentClient.args.NamePattern)
+	require.Equal(t, taskagent.SecureFileActionFilterValues.Use, *taskAgentClient.args.ActionFilter)
+
+	require.Equal(t, projectId, d.Id())
+	require.Equal(t, 2, d.Get(sfsSecureFiles+".#"))
+	require.Equal(t, "signing-a.p12", d.Get(sfsSecureFiles+".0."+sfName))
+	require.Equal(t, "00000000-0000-0000-0000-000000000001", d.Get(sfsSecureFiles+".1.id"))
+}
+
+func TestSecureFileMatches(t *testing.T) {
+	secureFile := taskagent.SecureFile{
+		Id:         &uuid.Nil,
+		Name:       utils.NewString("signing-cert.p12"),
+		Properties: &map[string]string{"team": "mobile"},
+	}
+
+	test := func(
+		namePrefix string, nameRegex *regexp.Regexp, properties map[string]string, expected bool,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			res := secureFileMatches(secureFile, namePrefix, nameRegex, properties)
+			require.Equal(t, expected, res)
+		}
+	}
+
+	t.Run("no_filters", test("", nil, nil, true))
+	t.Run("matching_prefix", test("signing-", nil, nil, true))
+	t.Run("matching_prefix_different_case", test("SIGNING-", nil, nil, true))
+	t.Run("non_matching_prefix", test("profile-", nil, nil, false))
+	t.Run("prefix_longer_than_name", test("signing-cert.p12.old", nil, nil, false))
+	t.Run("matching_regex", test("", regexp.MustCompile(`\.p12$`), nil, true))
+	t.Run("non_matching_regex", test("", regexp.MustCompile(`\.pfx$`), nil, false))
+	t.Run("matching_properties", test("", nil, map[string]string{"team": "mobile"}, true))
+	t.Run("non_matching_property_value", test("", nil, map[string]string{"team": "web"}, false))
+	t.Run("missing_property", test("", nil, map[string]string{"owner": "mobile"}, false))
+}
+
+func testAccDataSourceSecureFilesConfig(projectId string, prefix string) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "a" {
+  project_id = %[1]q
+  name = "%[2]s-a.txt"
+  content = "Hello World"
+  properties = {
+    team = "a"
+  }
+}
+
+resource "azdoext_secure_file" "b" {
+  project_id = %[1]q
+  name = "%[2]s-b.txt"
+  content = "Hello World"
+  properties = {
+    team = "b"
+  }
+}
+
+data "azdoext_secure_files" "all" {
+  project_id = %[1]q
+  name_prefix = %[2]q
+
+  depends_on = [azdoext_secure_file.a, azdoext_secure_file.b]
+}
+
+data "azdoext_secure_files" "filtered" {
+  project_id = %[1]q
+  name_regex = "^%[2]s-.*\\.txt$"
+  properties = {
+    team = "b"
+  }
+
+  depends_on = [azdoext_secure_file.a, azdoext_secure_file.b]
+}
+`, projectId, prefix,
+	)
+}
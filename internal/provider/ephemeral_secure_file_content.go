@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+)
+
+const (
+	sfcContentBase64 = "content_base64"
+	sfcSha256        = "sha256"
+)
+
+type secureFileContentEphemeralResource struct {
+	clients *client.Clients
+}
+
+type secureFileContentEphemeralResourceModel struct {
+	ProjectId     types.String `tfsdk:"project_id"`
+	Id            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	Sha256        types.String `tfsdk:"sha256"`
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure      = &secureFileContentEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &secureFileContentEphemeralResource{}
+)
+
+func ephemeralSecureFileContent() ephemeral.EphemeralResource {
+	return &secureFileContentEphemeralResource{}
+}
+
+func (r *secureFileContentEphemeralResource) Metadata(
+	_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_secure_file_content"
+}
+
+func (r *secureFileContentEphemeralResource) Schema(
+	_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Use this ephemeral resource to read the content of an existing secure file within Azure " +
+			"DevOps, without it being stored in the plan or state. Requires Terraform 1.10 or later.",
+
+		Attributes: map[string]schema.Attribute{
+			sfProjectId: schema.StringAttribute{
+				MarkdownDescription: "The ID of the Azure DevOps project the secure file belongs to.",
+				Required:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the secure file. Exactly one of **id** and **" + sfName +
+					"** must be set.",
+				Optional: true,
+				Computed: true,
+			},
+			sfName: schema.StringAttribute{
+				MarkdownDescription: "The name of the secure file. Exactly one of **id** and **" + sfName +
+					"** must be set.",
+				Optional: true,
+				Computed: true,
+			},
+			sfcContentBase64: schema.StringAttribute{
+				MarkdownDescription: "The base64 encoded content of the secure file.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			sfcSha256: schema.StringAttribute{
+				MarkdownDescription: "The hex encoded SHA-256 hash of the content of the secure file.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *secureFileContentEphemeralResource) Configure(
+	_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	clients, ok := req.ProviderData.(*client.Clients)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected provider data",
+			fmt.Sprintf("Expected *client.Clients, got %T. Please report this to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.clients = clients
+}
+
+func (r *secureFileContentEphemeralResource) ValidateConfig(
+	ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse,
+) {
+	var config secureFileContentEphemeralResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for attribute, value := range map[string]types.String{sfProjectId: config.ProjectId, "id": config.Id} {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		if _, err := uuid.Parse(value.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute), "Invalid UUID", fmt.Sprintf("%q must be a UUID: %v", attribute, err),
+			)
+		}
+	}
+
+	if config.Id.IsUnknown() || config.Name.IsUnknown() {
+		return
+	}
+	if config.Id.IsNull() == config.Name.IsNull() {
+		resp.Diagnostics.AddError(
+			"Invalid secure file reference", "Exactly one of \"id\" and \""+sfName+"\" must be set.",
+		)
+	}
+}
+
+func (r *secureFileContentEphemeralResource) Open(
+	ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse,
+) {
+	if r.clients == nil {
+		resp.Diagnostics.AddError(
+			"Provider not configured", "The provider must be configured before reading the content of a secure file.",
+		)
+		return
+	}
+
+	var config secureFileContentEphemeralResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	projectId := config.ProjectId.ValueString()
+
+	secureFile, content, err := readSecureFileContent(
+		r.clients, ctx, projectId, config.Id.ValueString(), config.Name.ValueString(),
+	)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading content of secure file in Azure DevOps", err.Error())
+		return
+	}
+
+	hash := sha256.Sum256(content)
+
+	config.Id = types.StringValue(secureFile.Id.String())
+	config.Name = types.StringValue(*secureFile.Name)
+	config.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+	config.Sha256 = types.StringValue(hex.EncodeToString(hash[:]))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &config)...)
+}
+
+// readSecureFileContent looks up a secure file by ID, or by name when no ID is given, and downloads its content.
+func readSecureFileContent(
+	clients *client.Clients, ctx context.Context, projectId string, secureFileId string, name string,
+) (*taskagent.SecureFile, []byte, error) {
+	var secureFile *taskagent.SecureFile
+	var content io.ReadCloser
+	if secureFileId == "" {
+		var err error
+		secureFile, err = getSecureFileByName(clients, ctx, &projectId, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"error looking up secure file given name (%v) and project ID (%v): %v", name, projectId, err,
+			)
+		}
+		if secureFile == nil {
+			return nil, nil, fmt.Errorf("secure file with name (%v) not found in project ID (%v)", name, projectId)
+		}
+
+		content, err = downloadSecureFileContent(clients, ctx, &projectId, secureFile.Id)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"error downloading secure file given ID (%v) and project ID (%v): %v", secureFile.Id, projectId, err,
+			)
+		}
+	} else {
+		id, err := uuid.Parse(secureFileId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing the secure file ID (%v): %v", secureFileId, err)
+		}
+
+		// The name of a secure file given by ID is only known from its metadata, which the download ticket is
+		// requested along with
+		includeDownloadTicket := true
+		secureFile, err = clients.TaskAgentClient.GetSecureFile(
+			ctx, taskagent.GetSecureFileArgs{
+				Project:               &projectId,
+				SecureFileId:          &id,
+				IncludeDownloadTicket: &includeDownloadTicket,
+			},
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"error looking up secure file given ID (%v) and project ID (%v): %v", id, projectId, err,
+			)
+		}
+
+		content, err = downloadSecureFile(clients, ctx, &projectId, secureFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"error downloading secure file given ID (%v) and project ID (%v): %v", id, projectId, err,
+			)
+		}
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secureFile, data, nil
+}
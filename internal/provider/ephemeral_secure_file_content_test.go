@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+func TestReadSecureFileContent(t *testing.T) {
+	secureFileId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	test := func(id string, name string, expectedErr string, expectedGetCalls int) func(*testing.T) {
+		return func(t *testing.T) {
+			var calls []string
+			taskAgentClient := &fakeTaskAgentClient{
+				content: "Hello World",
+				calls:   &calls,
+				secureFiles: []taskagent.SecureFile{
+					{Id: &secureFileId, Name: utils.NewString("hello_world.txt")},
+				},
+			}
+			clients := &client.Clients{TaskAgentClient: taskAgentClient}
+
+			secureFile, content, err := readSecureFileContent(clients, context.Background(), "project", id, name)
+			if expectedErr != "" {
+				require.ErrorContains(t, err, expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, secureFileId, *secureFile.Id)
+			require.Equal(t, "hello_world.txt", *secureFile.Name)
+			require.Equal(t, "Hello World", string(content))
+			require.Equal(t, expectedGetCalls, taskAgentClient.getCalls)
+		}
+	}
+
+	// The download ticket for a secure file given by ID is requested along with its metadata
+	t.Run("id", test(secureFileId.String(), "", "", 1))
+	t.Run("name", test("", "HELLO_WORLD.TXT", "", 1))
+	t.Run("missing_id", test("00000000-0000-0000-0000-000000000002", "", "secure file not found", 1))
+	t.Run("missing_name", test("", "missing.txt", "secure file with name (missing.txt) not found", 0))
+	t.Run("invalid_id", test("hello_world.txt", "", "error parsing the secure file ID (hello_world.txt)", 0))
+}
+
+func TestSecureFileContentEphemeralResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+
+	serverFactory, err := NewProtoV5ProviderServer(ctx, "dev")
+	require.NoError(t, err)
+	server := serverFactory()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	configType := schemaResp.EphemeralResourceSchemas["azdoext_secure_file_content"].ValueType()
+
+	stringValue := func(value string) tftypes.Value {
+		if value == "" {
+			return tftypes.NewValue(tftypes.String, nil)
+		}
+		return tftypes.NewValue(tftypes.String, value)
+	}
+
+	test := func(projectId string, id string, name string, expectedSummaries []string) func(*testing.T) {
+		return func(t *testing.T) {
+			config, err := tfprotov5.NewDynamicValue(
+				configType, tftypes.NewValue(
+					configType, map[string]tftypes.Value{
+						sfProjectId:      stringValue(projectId),
+						"id":             stringValue(id),
+						sfName:           stringValue(name),
+						sfcContentBase64: stringValue(""),
+						sfcSha256:        stringValue(""),
+					},
+				),
+			)
+			require.NoError(t, err)
+
+			resp, err := server.ValidateEphemeralResourceConfig(
+				ctx, &tfprotov5.ValidateEphemeralResourceConfigRequest{
+					TypeName: "azdoext_secure_file_content",
+					Config:   &config,
+				},
+			)
+			require.NoError(t, err)
+
+			summaries := make([]string, 0, len(resp.Diagnostics))
+			for _, diagnostic := range resp.Diagnostics {
+				summaries = append(summaries, diagnostic.Summary)
+			}
+			require.ElementsMatch(t, expectedSummaries, summaries)
+		}
+	}
+
+	projectId := "00000000-0000-0000-0000-000000000001"
+	secureFileId := "00000000-0000-0000-0000-000000000002"
+
+	t.Run("id", test(projectId, secureFileId, "", []string{}))
+	t.Run("name", test(projectId, "", "hello_world.txt", []string{}))
+	t.Run("neither", test(projectId, "", "", []string{"Invalid secure file reference"}))
+	t.Run("both", test(projectId, secureFileId, "hello_world.txt", []string{"Invalid secure file reference"}))
+	t.Run("invalid_project_id", test("project", secureFileId, "", []string{"Invalid UUID"}))
+	t.Run("invalid_id", test(projectId, "secure-file", "", []string{"Invalid UUID"}))
+}
+
+func TestAccEphemeralSecureFileContent(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_10_0),
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+				"echo": echoprovider.NewProviderServer(),
+			},
+			Steps: []resource.TestStep{
+				{
+					Config: testAccEphemeralSecureFileContentConfig(projectId, fileName, "Hello World"),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("echo.foo", "data.name", fileName),
+						resource.TestCheckResourceAttr("echo.foo", "data.content_base64", "SGVsbG8gV29ybGQ="),
+						resource.TestCheckResourceAttr("echo.foo", "data.sha256", sha256Hex("Hello World")),
+					),
+				},
+			},
+		},
+	)
+}
+
+func testAccEphemeralSecureFileContentConfig(projectId string, fileName string, content string) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name = %q
+  content = %q
+}
+
+ephemeral "azdoext_secure_file_content" "foo" {
+  project_id = azdoext_secure_file.foo.project_id
+  id = azdoext_secure_file.foo.id
+}
+
+provider "echo" {
+  data = ephemeral.azdoext_secure_file_content.foo
+}
+
+resource "echo" "foo" {}
+`, projectId, fileName, content,
+	)
+}
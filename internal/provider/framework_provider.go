@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	fwschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// frameworkProvider serves the resources and ephemeral resources implemented with terraform-plugin-framework. It is
+// muxed with the SDKv2 provider returned by New, so its schema must match that provider's exactly.
+type frameworkProvider struct {
+	version string
+	clients *sharedClients
+}
+
+type frameworkProviderModel struct {
+	OrgServiceUrl             types.String `tfsdk:"org_service_url"`
+	PersonalAccessToken       types.String `tfsdk:"personal_access_token"`
+	ClientId                  types.String `tfsdk:"client_id"`
+	TenantId                  types.String `tfsdk:"tenant_id"`
+	ClientSecret              types.String `tfsdk:"client_secret"`
+	ClientCertificatePath     types.String `tfsdk:"client_certificate_path"`
+	ClientCertificatePassword types.String `tfsdk:"client_certificate_password"`
+	UseOidc                   types.Bool   `tfsdk:"use_oidc"`
+	OidcToken                 types.String `tfsdk:"oidc_token"`
+	OidcTokenFilePath         types.String `tfsdk:"oidc_token_file_path"`
+	OidcRequestUrl            types.String `tfsdk:"oidc_request_url"`
+	OidcRequestToken          types.String `tfsdk:"oidc_request_token"`
+	OidcServiceConnectionId   types.String `tfsdk:"oidc_azure_service_connection_id"`
+	UseMsi                    types.Bool   `tfsdk:"use_msi"`
+	MsiEndpoint               types.String `tfsdk:"msi_endpoint"`
+	UseCli                    types.Bool   `tfsdk:"use_cli"`
+	CliPath                   types.String `tfsdk:"cli_path"`
+	AuthMethods               types.List   `tfsdk:"auth_methods"`
+}
+
+var _ fwprovider.ProviderWithEphemeralResources = &frameworkProvider{}
+
+func (p *frameworkProvider) Metadata(
+	_ context.Context, _ fwprovider.MetadataRequest, resp *fwprovider.MetadataResponse,
+) {
+	resp.TypeName = "azdoext"
+	resp.Version = p.version
+}
+
+func (p *frameworkProvider) Schema(_ context.Context, _ fwprovider.SchemaRequest, resp *fwprovider.SchemaResponse) {
+	resp.Schema = fwschema.Schema{
+		Attributes: map[string]fwschema.Attribute{
+			argOrgServiceUrl: fwschema.StringAttribute{
+				MarkdownDescription: descOrgServiceUrl,
+				Optional:            true,
+			},
+			argPersonalAccessToken: fwschema.StringAttribute{
+				MarkdownDescription: descPersonalAccessToken,
+				Sensitive:           true,
+				Optional:            true,
+			},
+			argClientId: fwschema.StringAttribute{
+				MarkdownDescription: descClientId,
+				Optional:            true,
+			},
+			argTenantId: fwschema.StringAttribute{
+				MarkdownDescription: descTenantId,
+				Optional:            true,
+			},
+			argClientSecret: fwschema.StringAttribute{
+				MarkdownDescription: descClientSecret,
+				Sensitive:           true,
+				Optional:            true,
+			},
+			argClientCertificatePath: fwschema.StringAttribute{
+				MarkdownDescription: descClientCertificatePath,
+				Optional:            true,
+			},
+			argClientCertificatePassword: fwschema.StringAttribute{
+				MarkdownDescription: descClientCertificatePassword,
+				Sensitive:           true,
+				Optional:            true,
+			},
+			argUseOidc: fwschema.BoolAttribute{
+				MarkdownDescription: descUseOidc,
+				Optional:            true,
+			},
+			argOidcToken: fwschema.StringAttribute{
+				MarkdownDescription: descOidcToken,
+				Sensitive:           true,
+				Optional:            true,
+			},
+			argOidcTokenFilePath: fwschema.StringAttribute{
+				MarkdownDescription: descOidcTokenFilePath,
+				Optional:            true,
+			},
+			argOidcRequestUrl: fwschema.StringAttribute{
+				MarkdownDescription: descOidcRequestUrl,
+				Optional:            true,
+			},
+			argOidcRequestToken: fwschema.StringAttribute{
+				MarkdownDescription: descOidcRequestToken,
+				Sensitive:           true,
+				Optional:            true,
+			},
+			argOidcServiceConnectionId: fwschema.StringAttribute{
+				MarkdownDescription: descOidcServiceConnectionId,
+				Optional:            true,
+			},
+			argUseMsi: fwschema.BoolAttribute{
+				MarkdownDescription: descUseMsi,
+				Optional:            true,
+			},
+			argMsiEndpoint: fwschema.StringAttribute{
+				MarkdownDescription: descMsiEndpoint,
+				Optional:            true,
+			},
+			argUseCli: fwschema.BoolAttribute{
+				MarkdownDescription: descUseCli,
+				Optional:            true,
+			},
+			argCliPath: fwschema.StringAttribute{
+				MarkdownDescription: descCliPath,
+				Optional:            true,
+			},
+			argAuthMethods: fwschema.ListAttribute{
+				MarkdownDescription: descAuthMethods,
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (p *frameworkProvider) Configure(
+	ctx context.Context, req fwprovider.ConfigureRequest, resp *fwprovider.ConfigureResponse,
+) {
+	var config frameworkProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var authMethods []string
+	if !config.AuthMethods.IsNull() {
+		resp.Diagnostics.Append(config.AuthMethods.ElementsAs(ctx, &authMethods, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	clients, diags := p.clients.get(
+		ctx, providerConfig{
+			OrgServiceUrl:             stringValueOrEnv(config.OrgServiceUrl, envOrgServiceUrl),
+			PersonalAccessToken:       stringValueOrEnv(config.PersonalAccessToken, envPersonalAccessToken),
+			ClientId:                  stringValueOrEnv(config.ClientId, envClientId),
+			TenantId:                  stringValueOrEnv(config.TenantId, envTenantId),
+			ClientSecret:              stringValueOrEnv(config.ClientSecret, envClientSecret),
+			ClientCertificatePath:     stringValueOrEnv(config.ClientCertificatePath, envClientCertificatePath),
+			ClientCertificatePassword: stringValueOrEnv(config.ClientCertificatePassword, envClientCertificatePassword),
+			UseOidc:                   boolValueOrEnv(config.UseOidc, envUseOidc),
+			OidcToken:                 stringValueOrEnv(config.OidcToken, envOidcToken),
+			OidcTokenFilePath:         stringValueOrEnv(config.OidcTokenFilePath, envOidcTokenFilePath),
+			OidcRequestUrl:            config.OidcRequestUrl.ValueString(),
+			OidcRequestToken:          config.OidcRequestToken.ValueString(),
+			OidcServiceConnectionId:   stringValueOrEnv(config.OidcServiceConnectionId, envOidcServiceConnectionId),
+			UseMsi:                    boolValueOrEnv(config.UseMsi, envUseMsi),
+			MsiEndpoint:               stringValueOrEnv(config.MsiEndpoint, envMsiEndpoint),
+			UseCli:                    boolValueOrEnv(config.UseCli, envUseCli),
+			CliPath:                   stringValueOrEnv(config.CliPath, envCliPath),
+			AuthMethods:               authMethods,
+		}.withOidcRequestFromEnv(), p.version, req.TerraformVersion,
+	)
+	resp.Diagnostics.Append(frameworkDiagnostics(diags)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.DataSourceData = clients
+	resp.ResourceData = clients
+	resp.EphemeralResourceData = clients
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		ephemeralSecureFileContent,
+	}
+}
+
+// stringValueOrEnv returns the configured value, or the value of the first of the environment variables which is set
+// when it is not configured, matching schema.EnvDefaultFunc and schema.MultiEnvDefaultFunc in the SDKv2 provider.
+func stringValueOrEnv(value types.String, envs ...string) string {
+	if !value.IsNull() {
+		return value.ValueString()
+	}
+	for _, env := range envs {
+		if envValue := os.Getenv(env); envValue != "" {
+			return envValue
+		}
+	}
+	return ""
+}
+
+// boolValueOrEnv returns the configured value, or the value of the environment variable when it is not configured.
+func boolValueOrEnv(value types.Bool, env string) bool {
+	if !value.IsNull() {
+		return value.ValueBool()
+	}
+	envValue, _ := strconv.ParseBool(os.Getenv(env))
+	return envValue
+}
+
+// frameworkDiagnostics converts diagnostics produced by code shared with the SDKv2 provider.
+func frameworkDiagnostics(diags diag.Diagnostics) fwdiag.Diagnostics {
+	var converted fwdiag.Diagnostics
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			converted.AddError(d.Summary, d.Detail)
+		} else {
+			converted.AddWarning(d.Summary, d.Detail)
+		}
+	}
+	return converted
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
@@ -13,10 +15,106 @@ import (
 )
 
 const (
-	argOrgServiceUrl       = "org_service_url"
-	envOrgServiceUrl       = "AZDO_ORG_SERVICE_URL"
-	argPersonalAccessToken = "personal_access_token"
-	envPersonalAccessToken = "AZDO_PERSONAL_ACCESS_TOKEN"
+	argOrgServiceUrl             = "org_service_url"
+	envOrgServiceUrl             = "AZDO_ORG_SERVICE_URL"
+	argPersonalAccessToken       = "personal_access_token"
+	envPersonalAccessToken       = "AZDO_PERSONAL_ACCESS_TOKEN"
+	argClientId                  = "client_id"
+	envClientId                  = "ARM_CLIENT_ID"
+	argTenantId                  = "tenant_id"
+	envTenantId                  = "ARM_TENANT_ID"
+	argClientSecret              = "client_secret"
+	envClientSecret              = "ARM_CLIENT_SECRET"
+	argClientCertificatePath     = "client_certificate_path"
+	envClientCertificatePath     = "ARM_CLIENT_CERTIFICATE_PATH"
+	argClientCertificatePassword = "client_certificate_password"
+	envClientCertificatePassword = "ARM_CLIENT_CERTIFICATE_PASSWORD"
+	argUseOidc                   = "use_oidc"
+	envUseOidc                   = "ARM_USE_OIDC"
+	argOidcToken                 = "oidc_token"
+	envOidcToken                 = "ARM_OIDC_TOKEN"
+	argOidcTokenFilePath         = "oidc_token_file_path"
+	envOidcTokenFilePath         = "ARM_OIDC_TOKEN_FILE_PATH"
+	argOidcRequestUrl            = "oidc_request_url"
+	argOidcRequestToken          = "oidc_request_token"
+	argOidcServiceConnectionId   = "oidc_azure_service_connection_id"
+	envOidcServiceConnectionId   = "ARM_OIDC_AZURE_SERVICE_CONNECTION_ID"
+	argUseMsi                    = "use_msi"
+	envUseMsi                    = "ARM_USE_MSI"
+	argMsiEndpoint               = "msi_endpoint"
+	envMsiEndpoint               = "ARM_MSI_ENDPOINT"
+	argUseCli                    = "use_cli"
+	envUseCli                    = "ARM_USE_CLI"
+	argCliPath                   = "cli_path"
+	envCliPath                   = "ARM_CLI_PATH"
+	argAuthMethods               = "auth_methods"
+)
+
+// oidcRequestEnvs are the pairs of environment variables the URL and bearer token to request a federated OIDC token
+// with are read from, in order of precedence. Each pair is read together, so that the URL set by one CI system is never
+// used with the token set by another.
+var oidcRequestEnvs = []struct {
+	url            string
+	token          string
+	azurePipelines bool
+}{
+	{url: "ARM_OIDC_REQUEST_URL", token: "ARM_OIDC_REQUEST_TOKEN"},
+	{url: "ACTIONS_ID_TOKEN_REQUEST_URL", token: "ACTIONS_ID_TOKEN_REQUEST_TOKEN"},
+	{url: "SYSTEM_OIDCREQUESTURI", token: "SYSTEM_ACCESSTOKEN", azurePipelines: true},
+}
+
+const (
+	descOrgServiceUrl = "The url of the Azure DevOps instance which should be used. Can also be set via the `" +
+		envOrgServiceUrl + "` environment variable."
+	descPersonalAccessToken = "The personal access token which should be used. Can also be set via the `" +
+		envPersonalAccessToken + "` environment variable."
+	descClientId = "The client ID of the service principal, or of the user-assigned managed identity, which should be " +
+		"used to authenticate with Microsoft Entra ID. It is ignored when no method using it is configured. Can also " +
+		"be set via the `" + envClientId + "` environment variable."
+	descTenantId = "The ID of the Microsoft Entra ID tenant the service principal belongs to, or which the Azure CLI " +
+		"requests access tokens from. Can also be set via the `" + envTenantId + "` environment variable."
+	descClientSecret = "The client secret which should be used to authenticate the service principal. Can also be " +
+		"set via the `" + envClientSecret + "` environment variable."
+	descClientCertificatePath = "The path of a PEM or PKCS#12 encoded certificate and RSA private key which should " +
+		"be used to authenticate the service principal. Can also be set via the `" + envClientCertificatePath +
+		"` environment variable."
+	descClientCertificatePassword = "The password used to decrypt a PKCS#12 encoded client certificate. Can also be " +
+		"set via the `" + envClientCertificatePassword + "` environment variable."
+	descUseOidc = "Whether to authenticate the service principal by exchanging a federated OIDC token, such as one " +
+		"issued to a GitHub Actions or Azure Pipelines job, for an access token. Can also be set via the `" +
+		envUseOidc + "` environment variable."
+	descOidcToken = "The federated OIDC token which should be used when **" + argUseOidc + "** is enabled. Can also " +
+		"be set via the `" + envOidcToken + "` environment variable."
+	descOidcTokenFilePath = "The path of a file containing the federated OIDC token which should be used when **" +
+		argUseOidc + "** is enabled. The file is read again whenever the access token is refreshed. Can also be set " +
+		"via the `" + envOidcTokenFilePath + "` environment variable."
+	descOidcRequestUrl = "The URL the federated OIDC token is requested from when **" + argUseOidc + "** is enabled " +
+		"and no token is given. When neither this nor **" + argOidcRequestToken + "** is set, both are read from " +
+		"the `ARM_OIDC_REQUEST_URL` and `ARM_OIDC_REQUEST_TOKEN`, `ACTIONS_ID_TOKEN_REQUEST_URL` and " +
+		"`ACTIONS_ID_TOKEN_REQUEST_TOKEN`, or `SYSTEM_OIDCREQUESTURI` and `SYSTEM_ACCESSTOKEN` environment " +
+		"variables, whichever URL is set first. The latter two are set by GitHub Actions and Azure Pipelines " +
+		"respectively."
+	descOidcRequestToken = "The bearer token used to request the federated OIDC token from **" + argOidcRequestUrl +
+		"**. Read from the environment together with **" + argOidcRequestUrl + "** when neither is set."
+	descOidcServiceConnectionId = "The ID of the Azure Resource Manager service connection the federated OIDC token " +
+		"is requested for when running in Azure Pipelines. The token is requested as in GitHub Actions when this is " +
+		"not set, unless the request URL is read from `SYSTEM_OIDCREQUESTURI`, which requires it. Can also be set " +
+		"via the `" + envOidcServiceConnectionId + "` environment variable."
+	descUseMsi = "Whether to authenticate with the managed identity of the Azure resource Terraform is running on. " +
+		"The user-assigned managed identity with the client ID given by **" + argClientId + "** is used when it is " +
+		"set. Can also be set via the `" + envUseMsi + "` environment variable."
+	descMsiEndpoint = "The URL of the Azure Instance Metadata Service compatible endpoint managed identity tokens are " +
+		"requested from. Defaults to the Azure Instance Metadata Service, or to the identity endpoint of App Service " +
+		"and similar hosts when running on one. Can also be set via the `" + envMsiEndpoint + "` environment variable."
+	descUseCli = "Whether to authenticate with the account the Azure CLI is logged in with. The Azure CLI is also " +
+		"used when no other credentials are configured and it is installed. Can also be set via the `" + envUseCli +
+		"` environment variable."
+	descCliPath = "The path of the Azure CLI executable. Defaults to `az` on the `PATH`. Can also be set via the `" +
+		envCliPath + "` environment variable."
+	descAuthMethods = "The authentication methods which may be used, in the order they are attempted. Only the " +
+		"methods which are configured are attempted, and the first to succeed is used. Defaults to `" +
+		authMethodPersonalAccessToken + "`, `" + authMethodServicePrincipal + "`, `" + authMethodOidc + "`, `" +
+		authMethodManagedIdentity + "` and `" + authMethodAzureCli + "`."
 )
 
 func init() {
@@ -42,90 +140,258 @@ func init() {
 }
 
 func New(version string) func() *schema.Provider {
+	return func() *schema.Provider {
+		return newProvider(version, &sharedClients{})
+	}
+}
+
+// newProvider returns the SDKv2 provider, which is configured with the clients created by shared.
+func newProvider(version string, shared *sharedClients) *schema.Provider {
 	rn := func(resourceName string) string {
 		return fmt.Sprintf("azdoext_%s", resourceName)
 	}
 
-	return func() *schema.Provider {
-		p := &schema.Provider{
-			DataSourcesMap: map[string]*schema.Resource{},
-			ResourcesMap: map[string]*schema.Resource{
-				rn("secure_file"): resourceSecureFile(),
-			},
-			Schema: map[string]*schema.Schema{
-				argOrgServiceUrl: {
-					Description: "The url of the Azure DevOps instance which should be used. Can also be set via the `" + envOrgServiceUrl + "` environment variable.",
-					Type:        schema.TypeString,
-					Optional:    true,
-					DefaultFunc: schema.EnvDefaultFunc(envOrgServiceUrl, nil),
-				},
-				argPersonalAccessToken: {
-					Description: "The personal access token which should be used. Can also be set via the `" + envPersonalAccessToken + "` environment variable.",
-					Type:        schema.TypeString,
-					Sensitive:   true,
-					Optional:    true,
-					DefaultFunc: schema.EnvDefaultFunc(envPersonalAccessToken, nil),
-				},
+	p := &schema.Provider{
+		DataSourcesMap: map[string]*schema.Resource{
+			rn("secure_file"):  dataSourceSecureFile(),
+			rn("secure_files"): dataSourceSecureFiles(),
+		},
+		ResourcesMap: map[string]*schema.Resource{
+			rn("pipeline_resource_authorization"): resourcePipelineResourceAuthorization(),
+			rn("secure_file"):                     resourceSecureFile(),
+			rn("secure_file_role_assignment"):     resourceSecureFileRoleAssignment(),
+			rn("secure_file_role_inheritance"):    resourceSecureFileRoleInheritance(),
+		},
+		Schema: map[string]*schema.Schema{
+			argOrgServiceUrl: {
+				Description: descOrgServiceUrl,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envOrgServiceUrl, nil),
 			},
-		}
+			argPersonalAccessToken: {
+				Description: descPersonalAccessToken,
+				Type:        schema.TypeString,
+				Sensitive:   true,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envPersonalAccessToken, nil),
+			},
+			argClientId: {
+				Description: descClientId,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envClientId, nil),
+			},
+			argTenantId: {
+				Description: descTenantId,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envTenantId, nil),
+			},
+			argClientSecret: {
+				Description: descClientSecret,
+				Type:        schema.TypeString,
+				Sensitive:   true,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envClientSecret, nil),
+			},
+			argClientCertificatePath: {
+				Description: descClientCertificatePath,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envClientCertificatePath, nil),
+			},
+			argClientCertificatePassword: {
+				Description: descClientCertificatePassword,
+				Type:        schema.TypeString,
+				Sensitive:   true,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envClientCertificatePassword, nil),
+			},
+			argUseOidc: {
+				Description: descUseOidc,
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envUseOidc, false),
+			},
+			argOidcToken: {
+				Description: descOidcToken,
+				Type:        schema.TypeString,
+				Sensitive:   true,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envOidcToken, nil),
+			},
+			argOidcTokenFilePath: {
+				Description: descOidcTokenFilePath,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envOidcTokenFilePath, nil),
+			},
+			argOidcRequestUrl: {
+				Description: descOidcRequestUrl,
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			argOidcRequestToken: {
+				Description: descOidcRequestToken,
+				Type:        schema.TypeString,
+				Sensitive:   true,
+				Optional:    true,
+			},
+			argOidcServiceConnectionId: {
+				Description: descOidcServiceConnectionId,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envOidcServiceConnectionId, nil),
+			},
+			argUseMsi: {
+				Description: descUseMsi,
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envUseMsi, false),
+			},
+			argMsiEndpoint: {
+				Description: descMsiEndpoint,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envMsiEndpoint, nil),
+			},
+			argUseCli: {
+				Description: descUseCli,
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envUseCli, false),
+			},
+			argCliPath: {
+				Description: descCliPath,
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(envCliPath, nil),
+			},
+			argAuthMethods: {
+				Description: descAuthMethods,
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
 
-		p.ConfigureContextFunc = configure(version, p)
+	p.ConfigureContextFunc = configure(version, p, shared)
 
-		return p
-	}
+	return p
 }
 
-func configure(version string, p *schema.Provider) func(context.Context, *schema.ResourceData) (
-	interface{}, diag.Diagnostics,
-) {
+func configure(
+	version string, p *schema.Provider, shared *sharedClients,
+) func(context.Context, *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	return func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		orgServiceUrl := d.Get(argOrgServiceUrl).(string)
-		personalAccessToken := d.Get(argPersonalAccessToken).(string)
-
-		var diags diag.Diagnostics
-
-		if strings.EqualFold(orgServiceUrl, "") {
-			diags = append(
-				diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "Organisation service URL not set",
-					Detail:   "The Azure DevOps organisation service URL must be set",
-				},
-			)
+		config := providerConfig{
+			OrgServiceUrl:             d.Get(argOrgServiceUrl).(string),
+			PersonalAccessToken:       d.Get(argPersonalAccessToken).(string),
+			ClientId:                  d.Get(argClientId).(string),
+			TenantId:                  d.Get(argTenantId).(string),
+			ClientSecret:              d.Get(argClientSecret).(string),
+			ClientCertificatePath:     d.Get(argClientCertificatePath).(string),
+			ClientCertificatePassword: d.Get(argClientCertificatePassword).(string),
+			UseOidc:                   d.Get(argUseOidc).(bool),
+			OidcToken:                 d.Get(argOidcToken).(string),
+			OidcTokenFilePath:         d.Get(argOidcTokenFilePath).(string),
+			OidcRequestUrl:            d.Get(argOidcRequestUrl).(string),
+			OidcRequestToken:          d.Get(argOidcRequestToken).(string),
+			OidcServiceConnectionId:   d.Get(argOidcServiceConnectionId).(string),
+			UseMsi:                    d.Get(argUseMsi).(bool),
+			MsiEndpoint:               d.Get(argMsiEndpoint).(string),
+			UseCli:                    d.Get(argUseCli).(bool),
+			CliPath:                   d.Get(argCliPath).(string),
 		}
-		if strings.EqualFold(personalAccessToken, "") {
-			diags = append(
-				diags, diag.Diagnostic{
-					Severity: diag.Error,
-					Summary:  "Personal access token not set",
-					Detail:   "The Azure DevOps personal access token must be set",
-				},
-			)
+		for _, method := range d.Get(argAuthMethods).([]interface{}) {
+			config.AuthMethods = append(config.AuthMethods, method.(string))
 		}
 
-		if len(diags) > 0 {
+		clients, diags := shared.get(ctx, config.withOidcRequestFromEnv(), version, p.TerraformVersion)
+		if diags.HasError() {
 			return nil, diags
 		}
 
-		options := client.Options{
-			OrganisationUrl:     orgServiceUrl,
-			PersonalAccessToken: personalAccessToken,
-			ProviderVersion:     version,
-			TerraformVersion:    p.TerraformVersion,
-		}
+		return clients, diags
+	}
+}
 
-		clients, err := options.Clients(ctx)
+// sharedClients creates the Azure DevOps clients once for both the SDKv2 and framework providers, which are each
+// configured by the mux server, so that credentials are acquired and their diagnostics reported only once.
+type sharedClients struct {
+	once    sync.Once
+	clients *client.Clients
+	diags   diag.Diagnostics
+}
+
+// get returns the clients created by the first call, along with its diagnostics. Later calls only repeat the
+// diagnostics when the clients could not be created.
+func (s *sharedClients) get(
+	ctx context.Context, config providerConfig, version string, terraformVersion string,
+) (*client.Clients, diag.Diagnostics) {
+	created := false
+	s.once.Do(
+		func() {
+			s.clients, s.diags = newClients(ctx, config, version, terraformVersion)
+			created = true
+		},
+	)
+	if created || s.diags.HasError() {
+		return s.clients, s.diags
+	}
+	return s.clients, nil
+}
+
+// newClients creates the Azure DevOps clients from the provider configuration, authenticating with the first method in
+// the credential chain which succeeds. It is shared by the SDKv2 and framework providers so that both are configured
+// identically. The failures of every method attempted are reported, as warnings when a later method succeeds,
+// alongside a warning naming the method selected.
+func newClients(
+	ctx context.Context, config providerConfig, version string, terraformVersion string,
+) (*client.Clients, diag.Diagnostics) {
+	candidates, failures := credentialChain(config)
 
+	for _, candidate := range candidates {
+		options := candidate.options
+		options.ProviderVersion = version
+		options.TerraformVersion = terraformVersion
+
+		clients, err := options.Clients(ctx)
 		if err != nil {
-			diags = append(
-				diags, diag.Diagnostic{
+			failures = append(
+				failures, diag.Diagnostic{
 					Severity: diag.Error,
-					Summary:  "Error initialising Azure DevOps clients",
+					Summary:  "Error initialising Azure DevOps clients with " + candidate.method.description,
+					Detail:   err.Error(),
 				},
 			)
-			return nil, diags
+			continue
 		}
 
-		return clients, nil
+		diags := make(diag.Diagnostics, 0, len(failures)+1)
+		for _, failure := range failures {
+			failure.Severity = diag.Warning
+			diags = append(diags, failure)
+		}
+		diags = append(
+			diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Authenticated with " + candidate.method.description,
+				Detail: fmt.Sprintf(
+					"The %q authentication method was selected, as the first method in %s which is configured "+
+						"and succeeded.", candidate.method.name, argAuthMethods,
+				),
+			},
+		)
+		tflog.Info(
+			ctx, "Authenticated with "+candidate.method.description, map[string]interface{}{
+				"auth_method": candidate.method.name,
+			},
+		)
+		return clients, diags
 	}
+
+	return nil, failures
 }
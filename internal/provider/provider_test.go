@@ -1,15 +1,27 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/require"
 )
 
-var providerFactories = map[string]func() (*schema.Provider, error){
-	"azdoext": func() (*schema.Provider, error) {
-		return New("dev")(), nil
+var protoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+	"azdoext": func() (tfprotov5.ProviderServer, error) {
+		serverFactory, err := NewProtoV5ProviderServer(context.Background(), "dev")
+		if err != nil {
+			return nil, err
+		}
+		return serverFactory(), nil
 	},
 }
 
@@ -19,6 +31,149 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func TestProtoV5ProviderServer(t *testing.T) {
+	ctx := context.Background()
+
+	serverFactory, err := NewProtoV5ProviderServer(ctx, "dev")
+	require.NoError(t, err)
+	server := serverFactory()
+
+	// The mux server fails to return a schema when the SDKv2 and framework provider schemas differ
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	require.Empty(t, schemaResp.Diagnostics)
+	for resourceType := range New("dev")().ResourcesMap {
+		require.Contains(t, schemaResp.ResourceSchemas, resourceType)
+	}
+	for dataSourceType := range New("dev")().DataSourcesMap {
+		require.Contains(t, schemaResp.DataSourceSchemas, dataSourceType)
+	}
+
+	upgradeSecureFileState := func(content string, contentBase64 string) func(*testing.T) {
+		return func(t *testing.T) {
+			// State written before the provider was muxed, lacking the attributes added since
+			upgradeResp, err := server.UpgradeResourceState(
+				ctx, &tfprotov5.UpgradeResourceStateRequest{
+					TypeName: "azdoext_secure_file",
+					Version:  0,
+					RawState: &tfprotov5.RawState{
+						JSON: []byte(`{
+							"id": "00000000-0000-0000-0000-000000000001",
+							"project_id": "00000000-0000-0000-0000-000000000002",
+							"name": "hello_world.txt",
+							"content": "` + content + `",
+							"content_base64": "` + contentBase64 + `",
+							"allow_access": false
+						}`),
+					},
+				},
+			)
+			require.NoError(t, err)
+			require.Empty(t, upgradeResp.Diagnostics)
+
+			upgradedState, err := upgradeResp.UpgradedState.Unmarshal(
+				schemaResp.ResourceSchemas["azdoext_secure_file"].ValueType(),
+			)
+			require.NoError(t, err)
+
+			var attributes map[string]tftypes.Value
+			require.NoError(t, upgradedState.As(&attributes))
+
+			var upgradedContent, upgradedOnConflict string
+			require.NoError(t, attributes[sfContent].As(&upgradedContent))
+			require.NoError(t, attributes[sfOnConflict].As(&upgradedOnConflict))
+			require.Equal(t, secureFileContentHash("Hello World"), upgradedContent)
+			require.Equal(t, secureFileOnConflictFail, upgradedOnConflict)
+			require.True(t, attributes[sfCreatedBy].IsNull())
+		}
+	}
+
+	t.Run("upgrade_secure_file_state_plaintext", upgradeSecureFileState("Hello World", ""))
+	t.Run(
+		"upgrade_secure_file_state_hashed",
+		upgradeSecureFileState(secureFileContentHash("Hello World"), secureFileContentHash("")),
+	)
+}
+
+func TestProtoV5ProviderServerConfigure(t *testing.T) {
+	ctx := context.Background()
+	organisation := newTestOrganisation(t, "Bearer cli-token")
+
+	var tokenRequests atomic.Int32
+	tokenEndpoint := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				tokenRequests.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(
+					map[string]interface{}{"error": "invalid_client", "error_description": "Invalid client secret."},
+				)
+			},
+		),
+	)
+	t.Cleanup(tokenEndpoint.Close)
+	t.Setenv(envAuthorityHost, tokenEndpoint.URL)
+
+	// The stub of the Azure CLI records each invocation, so that the test can count them
+	invocationsPath := filepath.Join(t.TempDir(), "invocations")
+	cliPath := filepath.Join(t.TempDir(), "az")
+	require.NoError(
+		t, os.WriteFile(
+			cliPath, []byte("#!/bin/sh\necho >> '"+invocationsPath+"'\n"+
+				"echo '{\"accessToken\": \"cli-token\", \"expires_on\": 4102444800}'\n"), 0700,
+		),
+	)
+
+	serverFactory, err := NewProtoV5ProviderServer(ctx, "dev")
+	require.NoError(t, err)
+	server := serverFactory()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	configType := schemaResp.Provider.ValueType().(tftypes.Object)
+
+	configured := map[string]tftypes.Value{
+		argOrgServiceUrl: tftypes.NewValue(tftypes.String, organisation.URL),
+		argClientId:      tftypes.NewValue(tftypes.String, "00000000-0000-0000-0000-000000000001"),
+		argTenantId:      tftypes.NewValue(tftypes.String, "00000000-0000-0000-0000-000000000002"),
+		argClientSecret:  tftypes.NewValue(tftypes.String, "secret"),
+		argUseCli:        tftypes.NewValue(tftypes.Bool, true),
+		argCliPath:       tftypes.NewValue(tftypes.String, cliPath),
+		argAuthMethods: tftypes.NewValue(
+			tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+				tftypes.NewValue(tftypes.String, authMethodServicePrincipal),
+				tftypes.NewValue(tftypes.String, authMethodAzureCli),
+			},
+		),
+	}
+	attributes := make(map[string]tftypes.Value, len(configType.AttributeTypes))
+	for name, attributeType := range configType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+		if value, ok := configured[name]; ok {
+			attributes[name] = value
+		}
+	}
+	config, err := tfprotov5.NewDynamicValue(configType, tftypes.NewValue(configType, attributes))
+	require.NoError(t, err)
+
+	configureResp, err := server.ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{Config: &config})
+	require.NoError(t, err)
+
+	// Credentials are acquired, and the failure of the service principal reported, only once for both providers
+	require.Len(t, configureResp.Diagnostics, 2)
+	require.Equal(t, tfprotov5.DiagnosticSeverityWarning, configureResp.Diagnostics[0].Severity)
+	require.Equal(
+		t, "Error initialising Azure DevOps clients with a service principal", configureResp.Diagnostics[0].Summary,
+	)
+	require.Equal(t, tfprotov5.DiagnosticSeverityWarning, configureResp.Diagnostics[1].Severity)
+	require.Equal(t, "Authenticated with the Azure CLI", configureResp.Diagnostics[1].Summary)
+	require.Equal(t, int32(1), tokenRequests.Load())
+	invocations, err := os.ReadFile(invocationsPath)
+	require.NoError(t, err)
+	require.Equal(t, "\n", string(invocations))
+}
+
 func preCheck(t *testing.T) {
 	if err := os.Getenv(envOrgServiceUrl); err == "" {
 		t.Fatal(envOrgServiceUrl + " must be set for acceptance tests")
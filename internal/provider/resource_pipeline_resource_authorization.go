@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/pipelinepermissions"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+const (
+	praProjectId    = "project_id"
+	praResourceType = "resource_type"
+	praResourceId   = "resource_id"
+	praPipelineId   = "pipeline_id"
+)
+
+func resourcePipelineResourceAuthorization() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages the authorization of pipelines to use a protected resource within Azure DevOps.\n\n" +
+			"When **" + praPipelineId + "** is set only that pipeline is authorized, otherwise all pipelines in the " +
+			"project are authorized to use the resource.\n\n" +
+			"Authorizing all pipelines to use a secure file managed by `azdoext_secure_file` conflicts with its **" +
+			sfAllowAccess + "** attribute, and authorizing individual pipelines conflicts with its **" + sfPipelineIds +
+			"** attribute when that is configured.",
+
+		CreateContext: resourcePipelineResourceAuthorizationCreate,
+		ReadContext:   resourcePipelineResourceAuthorizationRead,
+		DeleteContext: resourcePipelineResourceAuthorizationDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePipelineResourceAuthorizationImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			praProjectId: {
+				Description:  "The ID of the Azure DevOps project the resource belongs to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			praResourceType: {
+				Description: "The type of the resource. One of `" + strings.Join(pipelineResourceTypes, "`, `") +
+					"`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(pipelineResourceTypes, false),
+			},
+			praResourceId: {
+				Description:  "The ID of the resource.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			praPipelineId: {
+				Description:  "The ID of the pipeline to authorize. All pipelines are authorized when this is not set.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"id": {
+				Description: "The ID of this resource.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourcePipelineResourceAuthorizationCreate(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, resourceType, resourceId, pipelineId := expandPipelineResourceAuthorization(d)
+
+	err := setPipelineResourceAuthorization(clients, ctx, &projectId, resourceType, resourceId, pipelineId, true)
+	if err != nil {
+		return diag.Errorf("Error authorizing pipelines to use %s (%s) in Azure DevOps: %+v", resourceType, resourceId, err)
+	}
+
+	d.SetId(pipelineResourceAuthorizationId(projectId, resourceType, resourceId, pipelineId))
+
+	return resourcePipelineResourceAuthorizationRead(ctx, d, meta)
+}
+
+func resourcePipelineResourceAuthorizationRead(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, resourceType, resourceId, pipelineId := expandPipelineResourceAuthorization(d)
+
+	pipelinePermissions, err := getPipelinePermissions(clients, ctx, &projectId, resourceType, resourceId)
+	if err != nil {
+		if utils.ResponseWasNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf(
+			"Error looking up pipeline permissions given type (%v), ID (%v) and project ID (%v): %v", resourceType,
+			resourceId, projectId, err,
+		)
+	}
+
+	var authorized bool
+	if pipelineId == 0 {
+		authorized = isAllPipelinesAuthorized(pipelinePermissions)
+	} else {
+		authorized = slices.Contains(authorizedPipelineIds(pipelinePermissions), pipelineId)
+	}
+
+	if !authorized {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourcePipelineResourceAuthorizationDelete(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, resourceType, resourceId, pipelineId := expandPipelineResourceAuthorization(d)
+
+	err := setPipelineResourceAuthorization(clients, ctx, &projectId, resourceType, resourceId, pipelineId, false)
+	if err != nil {
+		return diag.Errorf(
+			"Error removing authorization of pipelines to use %s (%s) in Azure DevOps: %+v", resourceType, resourceId,
+			err,
+		)
+	}
+
+	return nil
+}
+
+func resourcePipelineResourceAuthorizationImport(
+	_ context.Context, d *schema.ResourceData, _ interface{},
+) ([]*schema.ResourceData, error) {
+	projectId, resourceType, resourceId, pipelineId, err := parsePipelineResourceAuthorizationId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	_ = d.Set(praProjectId, projectId)
+	_ = d.Set(praResourceType, resourceType)
+	_ = d.Set(praResourceId, resourceId)
+	if pipelineId != 0 {
+		_ = d.Set(praPipelineId, pipelineId)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandPipelineResourceAuthorization(d *schema.ResourceData) (string, string, string, int) {
+	return d.Get(praProjectId).(string), d.Get(praResourceType).(string), d.Get(praResourceId).(string),
+		d.Get(praPipelineId).(int)
+}
+
+// setPipelineResourceAuthorization authorizes all pipelines in the project when no pipeline ID is given, otherwise only
+// the given pipeline.
+func setPipelineResourceAuthorization(
+	clients *client.Clients, ctx context.Context, projectId *string, resourceType string, resourceId string,
+	pipelineId int, authorized bool,
+) error {
+	if pipelineId == 0 {
+		_, err := setAllPipelinesAuthorized(clients, ctx, projectId, resourceType, resourceId, authorized)
+		return err
+	}
+
+	_, err := updatePipelinePermissions(
+		clients, ctx, projectId, resourceType, resourceId, []pipelinepermissions.PipelinePermission{
+			newPipelinePermission(pipelineId, authorized),
+		},
+	)
+	return err
+}
+
+func pipelineResourceAuthorizationId(projectId string, resourceType string, resourceId string, pipelineId int) string {
+	id := fmt.Sprintf("%s/%s/%s", projectId, resourceType, resourceId)
+	if pipelineId != 0 {
+		id += fmt.Sprintf("/%d", pipelineId)
+	}
+	return id
+}
+
+func parsePipelineResourceAuthorizationId(id string) (string, string, string, int, error) {
+	parts := strings.Split(id, "/")
+	if (len(parts) != 3 && len(parts) != 4) || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", 0, fmt.Errorf(
+			"unexpected format of ID (%s), expected <project_id>/<resource_type>/<resource_id>[/<pipeline_id>]", id,
+		)
+	}
+
+	if !slices.Contains(pipelineResourceTypes, parts[1]) {
+		return "", "", "", 0, fmt.Errorf(
+			"unexpected resource type (%s) in ID (%s), expected one of %s", parts[1], id,
+			strings.Join(pipelineResourceTypes, ", "),
+		)
+	}
+
+	pipelineId := 0
+	if len(parts) == 4 {
+		var err error
+		pipelineId, err = strconv.Atoi(parts[3])
+		if err != nil || pipelineId < 1 {
+			return "", "", "", 0, fmt.Errorf("unexpected pipeline ID (%s) in ID (%s)", parts[3], id)
+		}
+	}
+
+	return parts[0], parts[1], parts[2], pipelineId, nil
+}
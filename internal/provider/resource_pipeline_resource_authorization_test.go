@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/pipelinepermissions"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+func TestAccResourcePipelineResourceAuthorization(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccResourcePipelineResourceAuthorizationConfig(projectId, fileName),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							"azdoext_pipeline_resource_authorization.foo", "resource_type", "securefile",
+						),
+						resource.TestCheckResourceAttrPair(
+							"azdoext_pipeline_resource_authorization.foo", "resource_id", "azdoext_secure_file.foo", "id",
+						),
+						resource.TestCheckNoResourceAttr("azdoext_pipeline_resource_authorization.foo", "pipeline_id"),
+					),
+				},
+				{
+					ResourceName:      "azdoext_pipeline_resource_authorization.foo",
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+			},
+		},
+	)
+}
+
+func TestParsePipelineResourceAuthorizationId(t *testing.T) {
+	test := func(id string, expectedPipelineId int, expectErr bool) func(*testing.T) {
+		return func(t *testing.T) {
+			projectId, resourceType, resourceId, pipelineId, err := parsePipelineResourceAuthorizationId(id)
+			if expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "project", projectId)
+			require.Equal(t, "securefile", resourceType)
+			require.Equal(t, "resource", resourceId)
+			require.Equal(t, expectedPipelineId, pipelineId)
+			require.Equal(t, id, pipelineResourceAuthorizationId(projectId, resourceType, resourceId, pipelineId))
+		}
+	}
+
+	t.Run("all_pipelines", test("project/securefile/resource", 0, false))
+	t.Run("pipeline", test("project/securefile/resource/42", 42, false))
+	t.Run("missing_resource_id", test("project/securefile", 0, true))
+	t.Run("empty_resource_id", test("project/securefile/", 0, true))
+	t.Run("invalid_resource_type", test("project/secretfile/resource", 0, true))
+	t.Run("resource_type_different_case", test("project/SecureFile/resource", 0, true))
+	t.Run("invalid_pipeline_id", test("project/securefile/resource/foo", 0, true))
+	t.Run("too_many_parts", test("project/securefile/resource/42/foo", 0, true))
+}
+
+func TestSetPipelineResourceAuthorization(t *testing.T) {
+	test := func(resourceType string, pipelineId int, authorized bool) func(*testing.T) {
+		return func(t *testing.T) {
+			var calls []string
+			pipelinePermissionsClient := &fakePipelinePermissionsClient{
+				permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{},
+				calls:       &calls,
+			}
+			clients := &client.Clients{
+				BuildClient:               &fakeBuildClient{calls: &calls},
+				PipelinePermissionsClient: pipelinePermissionsClient,
+			}
+
+			err := setPipelineResourceAuthorization(
+				clients, context.Background(), utils.NewString("project"), resourceType, "7", pipelineId, authorized,
+			)
+			require.NoError(t, err)
+			require.Equal(t, []string{"update permissions 7"}, calls)
+
+			permissions := pipelinePermissionsClient.permissions["7"]
+			if pipelineId == 0 {
+				require.Equal(t, &pipelinepermissions.Permission{Authorized: &authorized}, permissions.AllPipelines)
+				require.Nil(t, permissions.Pipelines)
+			} else {
+				require.Nil(t, permissions.AllPipelines)
+				require.Equal(
+					t, []pipelinepermissions.PipelinePermission{newPipelinePermission(pipelineId, authorized)},
+					*permissions.Pipelines,
+				)
+			}
+		}
+	}
+
+	// All pipelines are authorized through the pipeline permissions client, as the build client does not support
+	// authorizing environments or repositories
+	for _, resourceType := range pipelineResourceTypes {
+		t.Run(resourceType+"_authorize_all_pipelines", test(resourceType, 0, true))
+		t.Run(resourceType+"_unauthorize_all_pipelines", test(resourceType, 0, false))
+		t.Run(resourceType+"_authorize_pipeline", test(resourceType, 42, true))
+		t.Run(resourceType+"_unauthorize_pipeline", test(resourceType, 42, false))
+	}
+}
+
+func TestResourcePipelineResourceAuthorizationRead(t *testing.T) {
+	test := func(resourceType string, pipelineId int, expectedAuthorized bool) func(*testing.T) {
+		return func(t *testing.T) {
+			var calls []string
+			clients := &client.Clients{
+				BuildClient: &fakeBuildClient{calls: &calls},
+				PipelinePermissionsClient: &fakePipelinePermissionsClient{
+					permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{
+						"7": {
+							AllPipelines: &pipelinepermissions.Permission{Authorized: utils.NewBool(true)},
+							Pipelines: &[]pipelinepermissions.PipelinePermission{
+								newPipelinePermission(42, true),
+								newPipelinePermission(43, false),
+							},
+						},
+					},
+					calls: &calls,
+				},
+			}
+
+			raw := map[string]interface{}{
+				praProjectId:    "00000000-0000-0000-0000-000000000001",
+				praResourceType: resourceType,
+				praResourceId:   "7",
+			}
+			if pipelineId != 0 {
+				raw[praPipelineId] = pipelineId
+			}
+			d := schema.TestResourceDataRaw(t, resourcePipelineResourceAuthorization().Schema, raw)
+			d.SetId("some-id")
+
+			diags := resourcePipelineResourceAuthorizationRead(context.Background(), d, clients)
+			require.False(t, diags.HasError(), "%v", diags)
+			require.Equal(t, expectedAuthorized, d.Id() != "")
+		}
+	}
+
+	for _, resourceType := range pipelineResourceTypes {
+		t.Run(resourceType+"_all_pipelines", test(resourceType, 0, true))
+		t.Run(resourceType+"_authorized_pipeline", test(resourceType, 42, true))
+		t.Run(resourceType+"_unauthorized_pipeline", test(resourceType, 43, false))
+		t.Run(resourceType+"_unknown_pipeline", test(resourceType, 44, false))
+	}
+}
+
+func testAccResourcePipelineResourceAuthorizationConfig(projectId string, fileName string) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name       = %q
+  content    = "Hello World"
+
+  lifecycle {
+    ignore_changes = [allow_access]
+  }
+}
+
+resource "azdoext_pipeline_resource_authorization" "foo" {
+  project_id    = azdoext_secure_file.foo.project_id
+  resource_type = "securefile"
+  resource_id   = azdoext_secure_file.foo.id
+}
+`, projectId, fileName,
+	)
+}
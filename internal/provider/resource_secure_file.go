@@ -1,18 +1,34 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/pipelinepermissions"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
 
 	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
 	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
 	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
 )
@@ -22,22 +38,103 @@ const (
 	sfName          = "name"
 	sfContent       = "content"
 	sfContentBase64 = "content_base64"
+	sfSource        = "source"
+	sfContentWo     = "content_wo"
 	sfAllowAccess   = "allow_access"
+	sfPipelineIds   = "authorized_pipeline_ids"
 	sfProperties    = "properties"
+	sfDetectDrift   = "detect_content_drift"
+	sfOnConflict    = "on_conflict"
+	sfCreatedBy     = "created_by"
+	sfCreatedOn     = "created_on"
+	sfModifiedBy    = "modified_by"
+	sfModifiedOn    = "modified_on"
+
+	sfCreatedByDescriptor  = "created_by_descriptor"
+	sfModifiedByDescriptor = "modified_by_descriptor"
+
+	sfParseCertificates            = "parse_certificates"
+	sfCertificatePassword          = "certificate_password"
+	sfCertificateExpiryWarningDays = "certificate_expiry_warning_days"
+	sfCertificate                  = "certificate"
+
+	sfContentBase64Wo  = "content_base64_wo"
+	sfContentWoVersion = "content_wo_version"
 )
 
 const (
 	invalidSecureFileIdErrorMessageFormat = "Error parsing the secure file ID from the Terraform resource data: %v"
 )
 
+const (
+	secureFileOnConflictAdopt   = "adopt"
+	secureFileOnConflictReplace = "replace"
+	secureFileOnConflictFail    = "fail"
+)
+
+// secureFileContentAdopted is stored in place of the content hash when a secure file is imported, as it is not known
+// which content attribute is configured. The next apply compares the configured content against the secure file.
+const secureFileContentAdopted = "adopted"
+
+// secureFileContentKeys are the attributes which configure the content of a secure file. Write-only content is never
+// stored, so only changes to its version can be detected.
+var secureFileContentKeys = []string{sfContent, sfContentBase64, sfSource, sfContentWoVersion}
+
+// Uploads stream the whole content of the secure file and may be throttled, so creating and updating are given longer
+// than reading and deleting by default.
+const (
+	defaultSecureFileCreateTimeout = 10 * time.Minute
+	defaultSecureFileReadTimeout   = 5 * time.Minute
+	defaultSecureFileUpdateTimeout = 10 * time.Minute
+	defaultSecureFileDeleteTimeout = 5 * time.Minute
+)
+
+// secureFileSourceUnreadable is planned in place of the source hash when the source file cannot be read while planning.
+const secureFileSourceUnreadable = "unreadable"
+
 func resourceSecureFile() *schema.Resource {
 	return &schema.Resource{
-		Description: "Manages secure files within Azure DevOps.",
+		Description: "Manages secure files within Azure DevOps.\n\n" +
+			"Changing the content of a secure file uploads it as a new secure file under a temporary name, copies the " +
+			"pipeline authorizations and security role assignments of the existing secure file across to it, deletes " +
+			"the existing secure file and then renames the new one, so the ID of the secure file changes while its " +
+			"name remains available.\n\n" +
+			"The content of an imported secure file is not known until it is next applied, when the configured " +
+			"content is compared against the content of the secure file and only uploaded if it differs.\n\n" +
+			"Creating and updating a secure file time out after 10 minutes by default, and reading and deleting it " +
+			"after 5 minutes. These can be changed with a `timeouts` block.",
 
-		CreateContext: resourceSecureFileCreate,
-		ReadContext:   resourceSecureFileRead,
-		UpdateContext: resourceSecureFileUpdate,
-		DeleteContext: resourceSecureFileDelete,
+		CreateContext: withTimeoutDiagnostics(schema.TimeoutCreate, "creating", resourceSecureFileCreate),
+		ReadContext:   withTimeoutDiagnostics(schema.TimeoutRead, "reading", resourceSecureFileRead),
+		UpdateContext: withTimeoutDiagnostics(schema.TimeoutUpdate, "updating", resourceSecureFileUpdate),
+		DeleteContext: withTimeoutDiagnostics(schema.TimeoutDelete, "deleting", resourceSecureFileDelete),
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultSecureFileCreateTimeout),
+			Read:   schema.DefaultTimeout(defaultSecureFileReadTimeout),
+			Update: schema.DefaultTimeout(defaultSecureFileUpdateTimeout),
+			Delete: schema.DefaultTimeout(defaultSecureFileDeleteTimeout),
+		},
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSecureFileImport,
+		},
+
+		CustomizeDiff: resourceSecureFileCustomizeDiff,
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validateSecureFileWriteOnlyContent,
+			validateSecureFileSource,
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceSecureFileV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceSecureFileStateUpgradeV0,
+			},
+		},
 
 		Schema: map[string]*schema.Schema{
 			sfProjectId: {
@@ -58,9 +155,8 @@ func resourceSecureFile() *schema.Resource {
 				Type:          schema.TypeString,
 				Optional:      true,
 				Default:       "",
-				ForceNew:      true,
 				Sensitive:     true,
-				ConflictsWith: []string{sfContentBase64},
+				ConflictsWith: []string{sfContentBase64, sfSource, sfContentWo, sfContentBase64Wo},
 				StateFunc:     secureFileContentHash,
 			},
 			sfContentBase64: {
@@ -68,18 +164,64 @@ func resourceSecureFile() *schema.Resource {
 				Type:          schema.TypeString,
 				Optional:      true,
 				Default:       "",
-				ForceNew:      true,
 				Sensitive:     true,
-				ConflictsWith: []string{sfContent},
+				ConflictsWith: []string{sfContent, sfSource, sfContentWo, sfContentBase64Wo},
 				ValidateFunc:  utils.StringIsBase64Encoded,
 				StateFunc:     secureFileContentHash,
 			},
+			sfSource: {
+				Description: "The path of a local file to upload as the content of the secure file. " +
+					"The file is read when applying and only its hash is stored in state.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{sfContent, sfContentBase64, sfContentWo, sfContentBase64Wo},
+				StateFunc:     secureFileSourceHash,
+			},
+			sfContentWo: {
+				Description: "The plain-text content of the secure file, which is never stored in the plan or state. " +
+					"Requires Terraform 1.11 or later. Use **" + sfContentBase64Wo + "** for binary content to avoid " +
+					"issues. Must be set together with **" + sfContentWoVersion + "**.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				WriteOnly:     true,
+				ConflictsWith: []string{sfContent, sfContentBase64, sfSource, sfContentBase64Wo},
+				RequiredWith:  []string{sfContentWoVersion},
+			},
+			sfContentBase64Wo: {
+				Description: "The base64 encoded content of the secure file, which is never stored in the plan or " +
+					"state. Requires Terraform 1.11 or later. Must be set together with **" + sfContentWoVersion + "**.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				WriteOnly:     true,
+				ConflictsWith: []string{sfContent, sfContentBase64, sfSource, sfContentWo},
+				RequiredWith:  []string{sfContentWoVersion},
+				ValidateFunc:  utils.StringIsBase64Encoded,
+			},
+			sfContentWoVersion: {
+				Description: "The version of the content configured by **" + sfContentWo + "** or **" +
+					sfContentBase64Wo + "**. As write-only content cannot be compared against what was uploaded, " +
+					"the content is only uploaded again when this changes. Content drift is not detected for " +
+					"write-only content. Must be set together with **" + sfContentWo + "** or **" +
+					sfContentBase64Wo + "**.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
 			sfAllowAccess: {
 				Description: "Whether to allow all pipelines access to this resource.",
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 			},
+			sfPipelineIds: {
+				Description: "The IDs of the pipelines individually authorized to use this resource. " +
+					"Pipeline authorizations are left unmanaged when this is not configured, and all of them are revoked " +
+					"when this is configured as an empty set.",
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+				Optional: true,
+				Computed: true,
+			},
 			sfProperties: {
 				Description: "Properties assigned to the secure file.",
 				Type:        schema.TypeMap,
@@ -88,6 +230,86 @@ func resourceSecureFile() *schema.Resource {
 				},
 				Optional: true,
 			},
+			sfDetectDrift: {
+				Description: "Whether to download the content of the secure file when refreshing, so that changes made " +
+					"outside of Terraform cause the configured content to be uploaded again.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			sfParseCertificates: {
+				Description: "Whether to parse the certificates contained in the secure file while planning. " +
+					"Files with a `.p12`, `.pfx`, `.pem`, `.crt`, `.cer` or `.mobileprovision` extension are recognised.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			sfCertificatePassword: {
+				Description: "The password used to decrypt a `.p12` or `.pfx` secure file when parsing its certificates. " +
+					"Only its hash is stored in state.",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				StateFunc: secureFileCertificatePasswordHash,
+			},
+			sfCertificateExpiryWarningDays: {
+				Description: "The number of days before a parsed certificate or provisioning profile expires from " +
+					"which a warning is reported.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultCertificateExpiryWarningDays,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			sfCertificate: {
+				Description: "The certificates and provisioning profiles contained in the secure file, when **" +
+					sfParseCertificates + "** is enabled.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     secureFileCertificateResource(),
+			},
+			sfCreatedBy: {
+				Description: "The display name of the identity that created the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfCreatedByDescriptor: {
+				Description: "The descriptor of the identity that created the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfCreatedOn: {
+				Description: "The timestamp at which the secure file was created.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfModifiedBy: {
+				Description: "The display name of the identity that last modified the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfModifiedByDescriptor: {
+				Description: "The descriptor of the identity that last modified the secure file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfModifiedOn: {
+				Description: "The timestamp at which the secure file was last modified.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			sfOnConflict: {
+				Description: "What to do when creating the secure file and a secure file with the same name already " +
+					"exists in the project. `" + secureFileOnConflictAdopt + "` takes over the existing secure file " +
+					"without uploading the configured content, as when importing it, `" + secureFileOnConflictReplace +
+					"` deletes the existing secure file and uploads the configured content, and `" +
+					secureFileOnConflictFail + "` fails the plan.",
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  secureFileOnConflictFail,
+				ValidateFunc: validation.StringInSlice(
+					[]string{secureFileOnConflictAdopt, secureFileOnConflictReplace, secureFileOnConflictFail}, false,
+				),
+			},
 			"id": {
 				Description: "The ID of this resource.",
 				Type:        schema.TypeString,
@@ -97,30 +319,80 @@ func resourceSecureFile() *schema.Resource {
 	}
 }
 
+// resourceSecureFileCustomizeDiff plans the certificates contained in the secure file, checks that the name is not
+// already taken when creating or renaming the secure file, and plans the audit metadata as unknown when applying the
+// plan will change it, as replacing the content creates a new secure file and any other change modifies the existing
+// one.
+func resourceSecureFileCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeSecureFileCertificates(d); err != nil {
+		return err
+	}
+
+	if d.Id() == "" || d.HasChanges(sfProjectId, sfName) {
+		if err := customizeSecureFileNameCollision(ctx, d, meta); err != nil {
+			return err
+		}
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	if d.HasChanges(secureFileContentKeys...) {
+		for _, key := range []string{sfCreatedBy, sfCreatedByDescriptor, sfCreatedOn} {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.HasChanges(sfName, sfProperties) || d.HasChanges(secureFileContentKeys...) {
+		for _, key := range []string{sfModifiedBy, sfModifiedByDescriptor, sfModifiedOn} {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func resourceSecureFileCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clients := meta.(*client.Clients)
 
 	secureFile := expandSecureFile(d)
 	projectId := d.Get(sfProjectId).(string)
-	content := d.Get(sfContent).(string)
-	contentBase64 := d.Get(sfContentBase64).(string)
 
-	var data []byte
-	if content != "" {
-		data = []byte(content)
-	} else {
-		data, _ = base64.StdEncoding.DecodeString(contentBase64)
+	existingSecureFile, err := getSecureFileByName(clients, ctx, &projectId, *secureFile.Name)
+	if err != nil {
+		return diag.Errorf(
+			"Error looking up secure file given name (%v) and project ID (%v): %v", *secureFile.Name, projectId, err,
+		)
 	}
 
-	createdSecureFile, err := clients.TaskAgentClient.UploadSecureFile(
-		ctx, taskagent.UploadSecureFileArgs{
-			Project: &projectId,
-			Name:    secureFile.Name,
-			Content: &data,
-		},
-	)
-	if err != nil {
-		return diag.Errorf("Error creating secure file in Azure DevOps: %+v", err)
+	var createdSecureFile *taskagent.SecureFile
+	if existingSecureFile != nil {
+		switch d.Get(sfOnConflict).(string) {
+		case secureFileOnConflictAdopt:
+			createdSecureFile = existingSecureFile
+			_ = d.Set(sfContent, secureFileContentAdopted)
+			_ = d.Set(sfContentBase64, secureFileContentAdopted)
+			_ = d.Set(sfSource, secureFileContentAdopted)
+		case secureFileOnConflictReplace:
+			err = deleteSecureFile(clients, ctx, &projectId, existingSecureFile.Id)
+			if err != nil {
+				return diag.Errorf("Error replacing existing secure file in Azure DevOps: %+v", err)
+			}
+		default:
+			return diag.FromErr(secureFileNameCollisionError(projectId, existingSecureFile))
+		}
+	}
+
+	if createdSecureFile == nil {
+		createdSecureFile, err = uploadSecureFile(clients, ctx, d, &projectId, *secureFile.Name)
+		if err != nil {
+			return diag.Errorf("Error creating secure file in Azure DevOps: %+v", err)
+		}
 	}
 
 	createdSecureFile, err = updateSecureFile(clients, ctx, &projectId, createdSecureFile.Id, secureFile)
@@ -130,6 +402,12 @@ func resourceSecureFileCreate(ctx context.Context, d *schema.ResourceData, meta
 
 	flattenSecureFile(d, createdSecureFile, &projectId)
 
+	parsedCertificates, err := parseSecureFileCertificates(clients, ctx, d, &projectId, createdSecureFile.Id)
+	if err != nil {
+		return diag.Errorf("Error parsing certificates in secure file: %+v", err)
+	}
+	_ = d.Set(sfCertificate, parsedCertificates)
+
 	definitionResources := expandAllowAccess(d, createdSecureFile)
 	definitionResourceReferences, err := authorizeProjectReferences(clients, ctx, &projectId, definitionResources)
 	if err != nil {
@@ -138,9 +416,44 @@ func resourceSecureFileCreate(ctx context.Context, d *schema.ResourceData, meta
 
 	flattenAllowAccess(d, definitionResourceReferences)
 
+	if pipelineIdsConfigured(d) {
+		// An adopted secure file may already authorize pipelines, which are revoked unless they are configured
+		pipelineIds := d.Get(sfPipelineIds).(*schema.Set)
+		existingPipelineIds := schema.NewSet(pipelineIds.F, nil)
+		if existingSecureFile != nil && *existingSecureFile.Id == *createdSecureFile.Id {
+			pipelinePermissions, err := getPipelinePermissions(
+				clients, ctx, &projectId, secureFileResourceType, createdSecureFile.Id.String(),
+			)
+			if err != nil {
+				return diag.Errorf("Error looking up pipeline permissions for secure file in Azure DevOps: %+v", err)
+			}
+			for _, pipelineId := range authorizedPipelineIds(pipelinePermissions) {
+				existingPipelineIds.Add(pipelineId)
+			}
+		}
+
+		pipelinePermissions := expandPipelinePermissions(existingPipelineIds, pipelineIds)
+		_, err = updatePipelinePermissions(
+			clients, ctx, &projectId, secureFileResourceType, createdSecureFile.Id.String(), pipelinePermissions,
+		)
+		if err != nil {
+			return diag.Errorf("Error authorizing pipelines for secure file in Azure DevOps: %+v", err)
+		}
+	}
+
 	return resourceSecureFileRead(ctx, d, meta)
 }
 
+// pipelineIdsConfigured reports whether the authorized pipeline IDs are configured, including as an empty set, which
+// cannot be told apart from them not being configured once they have been read into the resource data.
+func pipelineIdsConfigured(d *schema.ResourceData) bool {
+	if config := d.GetRawConfig(); !config.IsNull() {
+		return !config.GetAttr(sfPipelineIds).IsNull()
+	}
+	_, ok := d.GetOk(sfPipelineIds)
+	return ok
+}
+
 func resourceSecureFileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clients := meta.(*client.Clients)
 
@@ -149,11 +462,16 @@ func resourceSecureFileRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf(invalidSecureFileIdErrorMessageFormat, err)
 	}
 
+	// Write-only content is never stored, so there is nothing to compare the downloaded content against
+	_, writeOnlyContent := d.GetOk(sfContentWoVersion)
+	detectDrift := d.Get(sfDetectDrift).(bool) && !writeOnlyContent
+
 	secureFile, err := clients.TaskAgentClient.GetSecureFile(
 		ctx,
 		taskagent.GetSecureFileArgs{
-			Project:      projectId,
-			SecureFileId: secureFileId,
+			Project:               projectId,
+			SecureFileId:          secureFileId,
+			IncludeDownloadTicket: &detectDrift,
 		},
 	)
 	if err != nil {
@@ -171,16 +489,22 @@ func resourceSecureFileRead(ctx context.Context, d *schema.ResourceData, meta in
 	}
 
 	flattenSecureFile(d, secureFile, projectId)
+	flattenSecureFileMetadata(d, secureFile)
 
-	resourceRefType := "securefile"
-	secFileId := secureFileId.String()
+	if detectDrift {
+		contentHash, contentBase64Hash, err := downloadSecureFileContentHashes(clients, ctx, projectId, secureFile)
+		if err != nil {
+			return diag.Errorf(
+				"Error downloading content of secure file given ID (%v) and project ID (%v): %v", secureFileId,
+				projectId, err,
+			)
+		}
 
-	projectResources, err := clients.BuildClient.GetProjectResources(
-		ctx, build.GetProjectResourcesArgs{
-			Project: projectId,
-			Type:    &resourceRefType,
-			Id:      &secFileId,
-		},
+		flattenSecureFileContentHashes(d, contentHash, contentBase64Hash)
+	}
+
+	projectResources, err := getProjectResources(
+		clients, ctx, projectId, secureFileResourceType, secureFileId.String(),
 	)
 	if err != nil {
 		return diag.Errorf(
@@ -190,7 +514,21 @@ func resourceSecureFileRead(ctx context.Context, d *schema.ResourceData, meta in
 
 	flattenAllowAccess(d, projectResources)
 
-	return nil
+	pipelinePermissions, err := getPipelinePermissions(
+		clients, ctx, projectId, secureFileResourceType, secureFileId.String(),
+	)
+	if err != nil {
+		return diag.Errorf(
+			"Error looking up pipeline permissions given ID (%v) and project ID (%v): %v", secureFileId, projectId, err,
+		)
+	}
+
+	flattenPipelinePermissions(d, pipelinePermissions)
+
+	return certificateExpiryWarnings(
+		d.Get(sfName).(string), d.Get(sfCertificate).([]interface{}), d.Get(sfCertificateExpiryWarningDays).(int),
+		time.Now(),
+	)
 }
 
 func resourceSecureFileUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -203,11 +541,39 @@ func resourceSecureFileUpdate(ctx context.Context, d *schema.ResourceData, meta
 
 	secureFile := expandSecureFile(d)
 
+	if d.HasChanges(secureFileContentKeys...) {
+		replaceContent := true
+		if oldContent, _ := d.GetChange(sfContent); oldContent == secureFileContentAdopted {
+			// The content of an adopted secure file is only replaced if it differs from the configured content
+			contentMatches, err := secureFileContentMatches(clients, ctx, d, projectId, secureFileId)
+			if err != nil {
+				return diag.Errorf("Error comparing content of adopted secure file in Azure DevOps: %+v", err)
+			}
+			replaceContent = !contentMatches
+		}
+
+		if replaceContent {
+			secureFileId, err = replaceSecureFileContent(clients, ctx, d, projectId, secureFileId, secureFile)
+			if err != nil {
+				return diag.Errorf("Error replacing content of secure file in Azure DevOps: %+v", err)
+			}
+			d.SetId(secureFileId.String())
+		}
+	}
+
 	updatedSecureFile, err := updateSecureFile(clients, ctx, projectId, secureFileId, secureFile)
 	if err != nil {
 		return diag.Errorf("Error updating secure file in Azure DevOps: %+v", err)
 	}
 
+	if d.HasChanges(sfName, sfCertificatePassword, sfParseCertificates) || d.HasChanges(secureFileContentKeys...) {
+		parsedCertificates, err := parseSecureFileCertificates(clients, ctx, d, projectId, secureFileId)
+		if err != nil {
+			return diag.Errorf("Error parsing certificates in secure file: %+v", err)
+		}
+		_ = d.Set(sfCertificate, parsedCertificates)
+	}
+
 	flattenSecureFile(d, updatedSecureFile, projectId)
 
 	definitionResources := expandAllowAccess(d, updatedSecureFile)
@@ -218,6 +584,17 @@ func resourceSecureFileUpdate(ctx context.Context, d *schema.ResourceData, meta
 
 	flattenAllowAccess(d, definitionResourceReferences)
 
+	if d.HasChange(sfPipelineIds) {
+		oldPipelineIds, newPipelineIds := d.GetChange(sfPipelineIds)
+		pipelinePermissions := expandPipelinePermissions(oldPipelineIds.(*schema.Set), newPipelineIds.(*schema.Set))
+		_, err = updatePipelinePermissions(
+			clients, ctx, projectId, secureFileResourceType, secureFileId.String(), pipelinePermissions,
+		)
+		if err != nil {
+			return diag.Errorf("Error updating pipeline permissions for secure file in Azure DevOps: %+v", err)
+		}
+	}
+
 	return resourceSecureFileRead(ctx, d, meta)
 }
 
@@ -229,11 +606,361 @@ func resourceSecureFileDelete(ctx context.Context, d *schema.ResourceData, meta
 		return diag.Errorf(invalidSecureFileIdErrorMessageFormat, err)
 	}
 
-	resourceRefType := "securefile"
+	err = deleteSecureFile(clients, ctx, projectId, secureFileId)
+	if err != nil {
+		return diag.Errorf("Error deleting secure file in Azure DevOps: %+v", err)
+	}
+
+	return nil
+}
+
+func resourceSecureFileImport(ctx context.Context, d *schema.ResourceData, meta interface{}) (
+	[]*schema.ResourceData, error,
+) {
+	clients := meta.(*client.Clients)
+
+	projectIdOrName, secureFileIdOrName, ok := strings.Cut(d.Id(), "/")
+	if !ok || projectIdOrName == "" || secureFileIdOrName == "" {
+		return nil, fmt.Errorf(
+			"unexpected format of ID (%s), expected <project_id_or_name>/<secure_file_id_or_name>", d.Id(),
+		)
+	}
+
+	projectId, err := resolveProjectId(clients, ctx, projectIdOrName)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up project (%s): %v", projectIdOrName, err)
+	}
+
+	var secureFile *taskagent.SecureFile
+	if secureFileId, err := uuid.Parse(secureFileIdOrName); err == nil {
+		secureFile, err = clients.TaskAgentClient.GetSecureFile(
+			ctx, taskagent.GetSecureFileArgs{
+				Project:      &projectId,
+				SecureFileId: &secureFileId,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error looking up secure file given ID (%v) and project ID (%v): %v", secureFileId, projectId, err,
+			)
+		}
+	} else {
+		secureFile, err = getSecureFileByName(clients, ctx, &projectId, secureFileIdOrName)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error looking up secure file given name (%v) and project ID (%v): %v", secureFileIdOrName, projectId,
+				err,
+			)
+		}
+		if secureFile == nil {
+			return nil, fmt.Errorf(
+				"secure file with name (%v) not found in project ID (%v)", secureFileIdOrName, projectId,
+			)
+		}
+	}
+
+	flattenSecureFile(d, secureFile, &projectId)
+	_ = d.Set(sfContent, secureFileContentAdopted)
+	_ = d.Set(sfContentBase64, secureFileContentAdopted)
+	_ = d.Set(sfSource, secureFileContentAdopted)
+	_ = d.Set(sfDetectDrift, false)
+	_ = d.Set(sfParseCertificates, false)
+	_ = d.Set(sfCertificatePassword, "")
+	_ = d.Set(sfCertificateExpiryWarningDays, defaultCertificateExpiryWarningDays)
+	_ = d.Set(sfOnConflict, secureFileOnConflictFail)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// withTimeoutDiagnostics reports which timeout was exceeded when an operation fails because its context's deadline
+// passed, as the errors returned by the clients only say that the context deadline was exceeded.
+func withTimeoutDiagnostics(
+	timeoutKey string, operation string,
+	f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics,
+) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		diags := f(ctx, d, meta)
+		if !diags.HasError() || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return diags
+		}
+		return append(diag.Diagnostics{secureFileTimeoutDiagnostic(timeoutKey, operation, d.Timeout(timeoutKey))}, diags...)
+	}
+}
+
+func secureFileTimeoutDiagnostic(timeoutKey string, operation string, timeout time.Duration) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Timed out %s secure file", operation),
+		Detail: fmt.Sprintf(
+			"Azure DevOps did not finish %s the secure file within the %s timeout of %s. Azure DevOps may be "+
+				"throttling requests to the organisation. The timeout can be increased with a timeouts block, "+
+				"for example:\n\n  timeouts {\n    %s = %q\n  }",
+			operation, timeoutKey, timeout, timeoutKey, 2*timeout,
+		),
+	}
+}
+
+// customizeSecureFileNameCollision fails the plan when another secure file in the project already has the planned name,
+// rather than leaving the upload to fail part way through applying.
+func customizeSecureFileNameCollision(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown(sfProjectId) || !d.NewValueKnown(sfName) {
+		return nil
+	}
+
+	projectId := d.Get(sfProjectId).(string)
+	secureFileId := d.Id()
+	if secureFileId == "" || d.HasChange(sfProjectId) {
+		// A new secure file will be created, in which case any conflict is resolved as configured while applying
+		if d.Get(sfOnConflict).(string) != secureFileOnConflictFail {
+			return nil
+		}
+		secureFileId = ""
+	}
+
+	return checkSecureFileNameAvailable(meta.(*client.Clients), ctx, projectId, d.Get(sfName).(string), secureFileId)
+}
+
+func checkSecureFileNameAvailable(
+	clients *client.Clients, ctx context.Context, projectId string, name string, secureFileId string,
+) error {
+	existingSecureFile, err := getSecureFileByName(clients, ctx, &projectId, name)
+	if err != nil {
+		return fmt.Errorf(
+			"error looking up secure file given name (%v) and project ID (%v): %v", name, projectId, err,
+		)
+	}
+	if existingSecureFile == nil || existingSecureFile.Id.String() == secureFileId {
+		return nil
+	}
+
+	return secureFileNameCollisionError(projectId, existingSecureFile)
+}
+
+func secureFileNameCollisionError(projectId string, existingSecureFile *taskagent.SecureFile) error {
+	return fmt.Errorf(
+		"a secure file named %q already exists in project ID (%v) with ID (%v), secure file names must be unique "+
+			"within a project. To manage the existing secure file, import it with: "+
+			"terraform import azdoext_secure_file.<name> %s/%s, or set %s to %q or %q",
+		*existingSecureFile.Name, projectId, existingSecureFile.Id, projectId, existingSecureFile.Id, sfOnConflict,
+		secureFileOnConflictAdopt, secureFileOnConflictReplace,
+	)
+}
+
+// secureFileContent streams the configured content of a secure file without buffering it in memory.
+type secureFileContent struct {
+	io.Reader
+	length     int64
+	source     *os.File
+	sourceHash hash.Hash
+}
+
+func openSecureFileContent(d *schema.ResourceData) (*secureFileContent, error) {
+	if config := d.GetRawConfig(); !config.IsNull() {
+		data, ok, err := writeOnlySecureFileContent(config)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &secureFileContent{
+				Reader: bytes.NewReader(data),
+				length: int64(len(data)),
+			}, nil
+		}
+	}
+
+	if source := d.Get(sfSource).(string); source != "" {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+
+		sourceHash := sha256.New()
+		return &secureFileContent{
+			Reader:     io.TeeReader(file, sourceHash),
+			length:     info.Size(),
+			source:     file,
+			sourceHash: sourceHash,
+		}, nil
+	}
+
+	if content := d.Get(sfContent).(string); content != "" {
+		return &secureFileContent{
+			Reader: strings.NewReader(content),
+			length: int64(len(content)),
+		}, nil
+	}
+
+	contentBase64 := d.Get(sfContentBase64).(string)
+	return &secureFileContent{
+		Reader: base64.NewDecoder(base64.StdEncoding, strings.NewReader(contentBase64)),
+		length: utils.Base64DecodedLen(contentBase64),
+	}, nil
+}
+
+// writeOnlySecureFileContent returns the write-only content configured in the raw configuration, as it is not available
+// from the resource data.
+func writeOnlySecureFileContent(config cty.Value) ([]byte, bool, error) {
+	if contentWo := config.GetAttr(sfContentWo); !contentWo.IsNull() {
+		return []byte(contentWo.AsString()), true, nil
+	}
+	if contentBase64Wo := config.GetAttr(sfContentBase64Wo); !contentBase64Wo.IsNull() {
+		data, err := base64.StdEncoding.DecodeString(contentBase64Wo.AsString())
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	}
+	return nil, false, nil
+}
+
+// validateSecureFileWriteOnlyContent checks that write-only content is configured whenever its version is, as
+// otherwise empty content would be uploaded. Write-only attributes cannot be required by the version through the schema
+// as either one of them may be configured.
+func validateSecureFileWriteOnlyContent(
+	_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse,
+) {
+	if !req.RawConfig.IsKnown() || req.RawConfig.IsNull() {
+		return
+	}
+
+	if req.RawConfig.GetAttr(sfContentWoVersion).IsNull() || !req.RawConfig.GetAttr(sfContentWo).IsNull() ||
+		!req.RawConfig.GetAttr(sfContentBase64Wo).IsNull() {
+		return
+	}
+
+	resp.Diagnostics = append(
+		resp.Diagnostics, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Missing write-only content",
+			Detail: fmt.Sprintf(
+				"%q is only used with write-only content, so either %q or %q must be configured with it.",
+				sfContentWoVersion, sfContentWo, sfContentBase64Wo,
+			),
+			AttributePath: cty.GetAttrPath(sfContentWoVersion),
+		},
+	)
+}
+
+// validateSecureFileSource reports a source file which cannot be read while planning. A source file which does not
+// exist yet only warns, as it may be created by another resource while applying, whereas any other error, such as a
+// lack of permissions or the source being a directory, fails the plan.
+func validateSecureFileSource(
+	_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse,
+) {
+	if !req.RawConfig.IsKnown() || req.RawConfig.IsNull() {
+		return
+	}
+
+	source := req.RawConfig.GetAttr(sfSource)
+	if !source.IsKnown() || source.IsNull() || source.AsString() == "" {
+		return
+	}
+
+	err := checkSecureFileSourceReadable(source.AsString())
+	if err == nil {
+		return
+	}
+
+	severity, summary := diag.Error, "Unreadable secure file source"
+	if errors.Is(err, fs.ErrNotExist) {
+		severity, summary = diag.Warning, "Missing secure file source"
+	}
+	resp.Diagnostics = append(
+		resp.Diagnostics, diag.Diagnostic{
+			Severity:      severity,
+			Summary:       summary,
+			Detail:        fmt.Sprintf("The source file of the secure file cannot be read: %v", err),
+			AttributePath: cty.GetAttrPath(sfSource),
+		},
+	)
+}
+
+// checkSecureFileSourceReadable checks that the source file exists, is a regular file and can be opened for reading.
+func checkSecureFileSourceReadable(source string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", source)
+	}
+	return nil
+}
+
+func (c *secureFileContent) Close() error {
+	if c.source != nil {
+		return c.source.Close()
+	}
+	return nil
+}
+
+// uploadSecureFile streams the configured content to a new secure file with the given name.
+func uploadSecureFile(
+	clients *client.Clients, ctx context.Context, d *schema.ResourceData, projectId *string, name string,
+) (*taskagent.SecureFile, error) {
+	content, err := openSecureFileContent(d)
+	if err != nil {
+		return nil, fmt.Errorf("error reading secure file content: %+v", err)
+	}
+	defer content.Close()
+
+	secureFile, err := clients.TaskAgentClient.UploadSecureFile(
+		ctx, taskagent.UploadSecureFileArgs{
+			Project:       projectId,
+			Name:          &name,
+			Content:       content,
+			ContentLength: content.length,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if content.sourceHash != nil {
+		// The file may have changed since it was hashed while planning, so record the hash of what was uploaded
+		_ = d.Set(sfSource, hex.EncodeToString(content.sourceHash.Sum(nil)))
+	}
+
+	return secureFile, nil
+}
+
+// deleteSecureFile removes the project-wide pipeline authorization for the secure file before deleting it.
+func deleteSecureFile(clients *client.Clients, ctx context.Context, projectId *string, secureFileId *uuid.UUID) error {
+	// Delete the secure file before removing its allow access, so that it is left untouched if it cannot be deleted
+	err := clients.TaskAgentClient.DeleteSecureFile(
+		ctx,
+		taskagent.DeleteSecureFileArgs{
+			Project:      projectId,
+			SecureFileId: secureFileId,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("error deleting secure file: %+v", err)
+	}
+
+	return removeSecureFileAllowAccess(clients, ctx, projectId, secureFileId)
+}
+
+func removeSecureFileAllowAccess(
+	clients *client.Clients, ctx context.Context, projectId *string, secureFileId *uuid.UUID,
+) error {
+	resourceRefType := secureFileResourceType
 	secFileId := secureFileId.String()
 	resourceRefName := ""
 	authorized := false
-	_, err = authorizeProjectReferences(
+	_, err := authorizeProjectReferences(
 		clients, ctx, projectId, []build.DefinitionResourceReference{
 			{
 				Type:       &resourceRefType,
@@ -244,21 +971,153 @@ func resourceSecureFileDelete(ctx context.Context, d *schema.ResourceData, meta
 		},
 	)
 	if err != nil {
-		return diag.Errorf(
-			"Error deleting the allow access definitionResource for secure file ID (%v) and project ID (%v): %v",
+		return fmt.Errorf(
+			"error deleting the allow access definitionResource for secure file ID (%v) and project ID (%v): %v",
 			secureFileId, projectId, err,
 		)
 	}
 
+	return nil
+}
+
+// replaceSecureFileContent uploads the configured content as a new secure file under a temporary name, copies the
+// pipeline authorizations and role assignments of the existing secure file across to it, then deletes the existing
+// secure file and only then removes its allow access. The new secure file is renamed by the caller once it has taken
+// the place of the existing one.
+func replaceSecureFileContent(
+	clients *client.Clients, ctx context.Context, d *schema.ResourceData, projectId *string,
+	secureFileId *uuid.UUID, secureFile taskagent.SecureFile,
+) (*uuid.UUID, error) {
+	temporaryName := fmt.Sprintf("%s.%s.tmp", *secureFile.Name, uuid.New())
+	replacementSecureFile, err := uploadSecureFile(clients, ctx, d, projectId, temporaryName)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading replacement secure file: %+v", err)
+	}
+
+	err = copySecureFilePipelinePermissions(clients, ctx, projectId, secureFileId, replacementSecureFile.Id)
+	if err != nil {
+		// Leave the existing secure file untouched, it is still in use
+		_ = clients.TaskAgentClient.DeleteSecureFile(
+			ctx, taskagent.DeleteSecureFileArgs{
+				Project:      projectId,
+				SecureFileId: replacementSecureFile.Id,
+			},
+		)
+		return nil, fmt.Errorf("error copying pipeline permissions to replacement secure file: %+v", err)
+	}
+
+	err = copySecureFileRoleAssignments(clients, ctx, *projectId, secureFileId, replacementSecureFile.Id)
+	if err != nil {
+		// Leave the existing secure file untouched, it is still in use
+		_ = clients.TaskAgentClient.DeleteSecureFile(
+			ctx, taskagent.DeleteSecureFileArgs{
+				Project:      projectId,
+				SecureFileId: replacementSecureFile.Id,
+			},
+		)
+		return nil, fmt.Errorf("error copying role assignments to replacement secure file: %+v", err)
+	}
+
 	err = clients.TaskAgentClient.DeleteSecureFile(
-		ctx,
-		taskagent.DeleteSecureFileArgs{
+		ctx, taskagent.DeleteSecureFileArgs{
 			Project:      projectId,
 			SecureFileId: secureFileId,
 		},
 	)
 	if err != nil {
-		return diag.Errorf("Error deleting secure file in Azure DevOps: %+v", err)
+		// The existing secure file is left untouched, so it remains usable by the pipelines authorized to use it
+		_ = clients.TaskAgentClient.DeleteSecureFile(
+			ctx, taskagent.DeleteSecureFileArgs{
+				Project:      projectId,
+				SecureFileId: replacementSecureFile.Id,
+			},
+		)
+		return nil, fmt.Errorf("error deleting existing secure file: %+v", err)
+	}
+
+	// The replacement has already taken the place of the existing secure file, so failing to clean up after the
+	// existing secure file does not fail the replacement
+	err = removeSecureFileAllowAccess(clients, ctx, projectId, secureFileId)
+	if err != nil {
+		tflog.Warn(ctx, "Unable to remove allow access of replaced secure file", map[string]interface{}{"error": err})
+	}
+
+	return replacementSecureFile.Id, nil
+}
+
+func copySecureFilePipelinePermissions(
+	clients *client.Clients, ctx context.Context, projectId *string, fromSecureFileId *uuid.UUID,
+	toSecureFileId *uuid.UUID,
+) error {
+	resourceType := secureFileResourceType
+	toId := toSecureFileId.String()
+
+	permissions, err := getPipelinePermissions(
+		clients, ctx, projectId, secureFileResourceType, fromSecureFileId.String(),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = clients.PipelinePermissionsClient.UpdatePipelinePermisionsForResource(
+		ctx, pipelinepermissions.UpdatePipelinePermisionsForResourceArgs{
+			Project:      projectId,
+			ResourceType: &resourceType,
+			ResourceId:   &toId,
+			ResourceAuthorization: &pipelinepermissions.ResourcePipelinePermissions{
+				AllPipelines: permissions.AllPipelines,
+				Pipelines:    permissions.Pipelines,
+			},
+		},
+	)
+	return err
+}
+
+// copySecureFileRoleAssignments assigns the roles explicitly assigned on one secure file on another, and stops the
+// other inheriting role assignments from the library when the one does not.
+func copySecureFileRoleAssignments(
+	clients *client.Clients, ctx context.Context, projectId string, fromSecureFileId *uuid.UUID,
+	toSecureFileId *uuid.UUID,
+) error {
+	scopeId, fromResourceId := secureFileRoleAssignmentScope(projectId, fromSecureFileId.String())
+	_, toResourceId := secureFileRoleAssignmentScope(projectId, toSecureFileId.String())
+
+	roleAssignments, err := getSecureFileRoleAssignments(clients, ctx, scopeId, fromResourceId)
+	if err != nil {
+		return err
+	}
+
+	var assignedRoles []securityroles.UserRoleAssignmentRef
+	for _, roleAssignment := range *roleAssignments {
+		if roleAssignment.Access == nil || *roleAssignment.Access != securityroles.RoleAccessValues.Assigned ||
+			roleAssignment.Identity == nil || roleAssignment.Identity.Id == nil ||
+			roleAssignment.Role == nil || roleAssignment.Role.Name == nil {
+			continue
+		}
+		identityId, err := uuid.Parse(*roleAssignment.Identity.Id)
+		if err != nil {
+			return err
+		}
+		assignedRoles = append(
+			assignedRoles, securityroles.UserRoleAssignmentRef{RoleName: roleAssignment.Role.Name, UserId: &identityId},
+		)
+	}
+
+	if len(assignedRoles) > 0 {
+		_, err = clients.SecurityRolesClient.SetRoleAssignments(
+			ctx, securityroles.SetRoleAssignmentsArgs{
+				ScopeId:         &scopeId,
+				ResourceId:      &toResourceId,
+				RoleAssignments: &assignedRoles,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if inheritPermissions, ok := findInheritPermissions(roleAssignments); ok && !inheritPermissions {
+		return setSecureFileInheritPermissions(clients, ctx, scopeId, toResourceId, false)
 	}
 
 	return nil
@@ -283,6 +1142,161 @@ func flattenSecureFile(d *schema.ResourceData, secureFile *taskagent.SecureFile,
 	_ = d.Set(sfProperties, secureFile.Properties)
 }
 
+func flattenSecureFileMetadata(d *schema.ResourceData, secureFile *taskagent.SecureFile) {
+	_ = d.Set(sfCreatedBy, flattenIdentityDisplayName(secureFile.CreatedBy))
+	_ = d.Set(sfCreatedByDescriptor, flattenIdentityDescriptor(secureFile.CreatedBy))
+	_ = d.Set(sfCreatedOn, flattenTime(secureFile.CreatedOn))
+	_ = d.Set(sfModifiedBy, flattenIdentityDisplayName(secureFile.ModifiedBy))
+	_ = d.Set(sfModifiedByDescriptor, flattenIdentityDescriptor(secureFile.ModifiedBy))
+	_ = d.Set(sfModifiedOn, flattenTime(secureFile.ModifiedOn))
+}
+
+func flattenIdentityDisplayName(identity *webapi.IdentityRef) string {
+	if identity == nil || identity.DisplayName == nil {
+		return ""
+	}
+	return *identity.DisplayName
+}
+
+func flattenIdentityDescriptor(identity *webapi.IdentityRef) string {
+	if identity == nil || identity.Descriptor == nil {
+		return ""
+	}
+	return *identity.Descriptor
+}
+
+func flattenTime(t *azuredevops.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+// flattenSecureFileContentHashes records the hash of the secure file's actual content against whichever content
+// attribute is in use, so that any difference from the configured content is planned as a change.
+func flattenSecureFileContentHashes(d *schema.ResourceData, contentHash string, contentBase64Hash string) {
+	content := d.Get(sfContent).(string)
+	contentBase64 := d.Get(sfContentBase64).(string)
+	source := d.Get(sfSource).(string)
+
+	if content == secureFileContentAdopted || contentBase64 == secureFileContentAdopted ||
+		source == secureFileContentAdopted {
+		return
+	}
+
+	if source != "" {
+		if source != contentBase64Hash {
+			_ = d.Set(sfSource, contentBase64Hash)
+		}
+	} else if content != secureFileContentHash("") {
+		if content != contentHash {
+			_ = d.Set(sfContent, contentHash)
+		}
+	} else if contentBase64 != contentBase64Hash {
+		_ = d.Set(sfContentBase64, contentBase64Hash)
+	}
+}
+
+// downloadSecureFileContent requests a download ticket for the secure file and opens its content.
+func downloadSecureFileContent(
+	clients *client.Clients, ctx context.Context, projectId *string, secureFileId *uuid.UUID,
+) (io.ReadCloser, error) {
+	includeDownloadTicket := true
+	secureFile, err := clients.TaskAgentClient.GetSecureFile(
+		ctx, taskagent.GetSecureFileArgs{
+			Project:               projectId,
+			SecureFileId:          secureFileId,
+			IncludeDownloadTicket: &includeDownloadTicket,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return downloadSecureFile(clients, ctx, projectId, secureFile)
+}
+
+// downloadSecureFile opens the content of the secure file using the download ticket issued along with it.
+func downloadSecureFile(
+	clients *client.Clients, ctx context.Context, projectId *string, secureFile *taskagent.SecureFile,
+) (io.ReadCloser, error) {
+	if secureFile.Ticket == nil {
+		return nil, fmt.Errorf("no download ticket was issued for the secure file")
+	}
+
+	return clients.TaskAgentClient.DownloadSecureFile(
+		ctx, taskagent.DownloadSecureFileArgs{
+			Project:      projectId,
+			SecureFileId: secureFile.Id,
+			Ticket:       secureFile.Ticket,
+		},
+	)
+}
+
+// secureFileContentMatches reports whether the configured content is identical to the content of the secure file.
+func secureFileContentMatches(
+	clients *client.Clients, ctx context.Context, d *schema.ResourceData, projectId *string, secureFileId *uuid.UUID,
+) (bool, error) {
+	download, err := downloadSecureFileContent(clients, ctx, projectId, secureFileId)
+	if err != nil {
+		return false, fmt.Errorf("error downloading secure file content: %+v", err)
+	}
+	defer download.Close()
+
+	downloadHash := sha256.New()
+	if _, err := io.Copy(downloadHash, download); err != nil {
+		return false, fmt.Errorf("error downloading secure file content: %+v", err)
+	}
+
+	content, err := openSecureFileContent(d)
+	if err != nil {
+		return false, fmt.Errorf("error reading secure file content: %+v", err)
+	}
+	defer content.Close()
+
+	contentHash := sha256.New()
+	if _, err := io.Copy(contentHash, content); err != nil {
+		return false, fmt.Errorf("error reading secure file content: %+v", err)
+	}
+
+	return bytes.Equal(downloadHash.Sum(nil), contentHash.Sum(nil)), nil
+}
+
+// downloadSecureFileContentHashes streams the content of the secure file, returning the hashes it would have been
+// stored with in state by secureFileContentHash had it been configured as content and as content_base64 respectively.
+// The latter is also the hash secureFileSourceHash would store for a source file with the same content.
+func downloadSecureFileContentHashes(
+	clients *client.Clients, ctx context.Context, projectId *string, secureFile *taskagent.SecureFile,
+) (string, string, error) {
+	content, err := downloadSecureFile(clients, ctx, projectId, secureFile)
+	if err != nil {
+		return "", "", err
+	}
+	defer content.Close()
+
+	// Plain-text content which happens to be valid base64 is hashed in its decoded form by secureFileContentHash
+	rawHash := sha256.New()
+	decodedHash := sha256.New()
+	_, err = io.Copy(decodedHash, base64.NewDecoder(base64.StdEncoding, io.TeeReader(content, rawHash)))
+
+	var corruptInputErr base64.CorruptInputError
+	isBase64 := err == nil
+	if errors.As(err, &corruptInputErr) {
+		_, err = io.Copy(rawHash, content)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	contentBase64Hash := hex.EncodeToString(rawHash.Sum(nil))
+	contentHash := contentBase64Hash
+	if isBase64 {
+		contentHash = hex.EncodeToString(decodedHash.Sum(nil))
+	}
+
+	return contentHash, contentBase64Hash, nil
+}
+
 func updateSecureFile(
 	clients *client.Clients, ctx context.Context, projectId *string, secureFileId *uuid.UUID,
 	secureFile taskagent.SecureFile,
@@ -296,6 +1310,59 @@ func updateSecureFile(
 	)
 }
 
+// getSecureFileByName looks up a secure file by its name, ignoring case. Names containing a comma cannot be looked up
+// by name, as Azure DevOps splits the names it is given on commas, so those are matched against the secure files whose
+// names match the name as a pattern instead.
+func getSecureFileByName(
+	clients *client.Clients, ctx context.Context, projectId *string, name string,
+) (*taskagent.SecureFile, error) {
+	var secureFiles *[]taskagent.SecureFile
+	var err error
+	if strings.Contains(name, ",") {
+		secureFiles, err = clients.TaskAgentClient.GetSecureFiles(
+			ctx, taskagent.GetSecureFilesArgs{
+				Project:     projectId,
+				NamePattern: &name,
+			},
+		)
+	} else {
+		secureFiles, err = clients.TaskAgentClient.GetSecureFilesByNames(
+			ctx, taskagent.GetSecureFilesByNamesArgs{
+				Project:         projectId,
+				SecureFileNames: &[]string{name},
+			},
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, secureFile := range *secureFiles {
+		if secureFile.Name != nil && strings.EqualFold(*secureFile.Name, name) {
+			return &secureFile, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func resolveProjectId(clients *client.Clients, ctx context.Context, projectIdOrName string) (string, error) {
+	if _, err := uuid.Parse(projectIdOrName); err == nil {
+		return projectIdOrName, nil
+	}
+
+	project, err := clients.CoreClient.GetProject(
+		ctx, core.GetProjectArgs{
+			ProjectId: &projectIdOrName,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return project.Id.String(), nil
+}
+
 func parseSecureFileAndProjectIds(d *schema.ResourceData) (*uuid.UUID, *string, error) {
 	secureFileId, err := uuid.Parse(d.Id())
 	if err != nil {
@@ -308,7 +1375,7 @@ func parseSecureFileAndProjectIds(d *schema.ResourceData) (*uuid.UUID, *string,
 }
 
 func expandAllowAccess(d *schema.ResourceData, secureFile *taskagent.SecureFile) []build.DefinitionResourceReference {
-	resourceRefType := "securefile"
+	resourceRefType := secureFileResourceType
 	secureFileId := secureFile.Id.String()
 	authorized := d.Get(sfAllowAccess).(bool)
 
@@ -323,28 +1390,17 @@ func expandAllowAccess(d *schema.ResourceData, secureFile *taskagent.SecureFile)
 }
 
 func flattenAllowAccess(d *schema.ResourceData, definitionResources *[]build.DefinitionResourceReference) {
-	secureFileId := d.Id()
-	var allowAccess = false
-	if definitionResources != nil {
-		for _, resource := range *definitionResources {
-			if secureFileId == *resource.Id {
-				allowAccess = *resource.Authorized
-			}
-		}
-	}
-	_ = d.Set(sfAllowAccess, allowAccess)
+	_ = d.Set(sfAllowAccess, isProjectResourceAuthorized(definitionResources, d.Id()))
 }
 
-func authorizeProjectReferences(
-	clients *client.Clients, ctx context.Context, projectId *string,
-	definitionResources []build.DefinitionResourceReference,
-) (*[]build.DefinitionResourceReference, error) {
-	return clients.BuildClient.AuthorizeProjectResources(
-		ctx, build.AuthorizeProjectResourcesArgs{
-			Project:   projectId,
-			Resources: &definitionResources,
-		},
-	)
+func flattenPipelinePermissions(
+	d *schema.ResourceData, pipelinePermissions *pipelinepermissions.ResourcePipelinePermissions,
+) {
+	pipelineIds := schema.NewSet(schema.HashInt, nil)
+	for _, pipelineId := range authorizedPipelineIds(pipelinePermissions) {
+		pipelineIds.Add(pipelineId)
+	}
+	_ = d.Set(sfPipelineIds, pipelineIds)
 }
 
 func secureFileContentHash(v interface{}) string {
@@ -360,3 +1416,35 @@ func secureFileContentHash(v interface{}) string {
 		return ""
 	}
 }
+
+// secureFileCertificatePasswordHash stores the hash of a certificate password in state, which is enough to tell when
+// the password changes and the certificates must be parsed again.
+func secureFileCertificatePasswordHash(v interface{}) string {
+	password, ok := v.(string)
+	if !ok || password == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(hash[:])
+}
+
+func secureFileSourceHash(v interface{}) string {
+	path, ok := v.(string)
+	if !ok || path == "" {
+		return ""
+	}
+
+	// Files which cannot be read yet, such as those created during the same apply, must still plan a change
+	file, err := os.Open(path)
+	if err != nil {
+		return secureFileSourceUnreadable
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return secureFileSourceUnreadable
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+const (
+	sfraProjectId    = "project_id"
+	sfraSecureFileId = "secure_file_id"
+	sfraIdentityId   = "identity_id"
+	sfraRole         = "role"
+)
+
+const (
+	secureFileSecurityRoleScope = "distributedtask.securefile"
+	libraryRoleScope            = "distributedtask.library"
+)
+
+var (
+	secureFileRoles = []string{"Reader", "User", "Administrator"}
+	libraryRoles    = []string{"Reader", "Creator", "User", "Administrator"}
+)
+
+func resourceSecureFileRoleAssignment() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages the assignment of a security role to a user or group on a secure file, or on the " +
+			"secure file library of a project, within Azure DevOps.\n\n" +
+			"Whether role assignments are inherited from the parent scope is managed for the secure file or library " +
+			"as a whole by `azdoext_secure_file_role_inheritance`.",
+
+		CreateContext: resourceSecureFileRoleAssignmentCreate,
+		ReadContext:   resourceSecureFileRoleAssignmentRead,
+		UpdateContext: resourceSecureFileRoleAssignmentUpdate,
+		DeleteContext: resourceSecureFileRoleAssignmentDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSecureFileRoleAssignmentImport,
+		},
+
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+			validateSecureFileRoleAssignmentRole,
+		},
+
+		Schema: map[string]*schema.Schema{
+			sfraProjectId: {
+				Description:  "The ID of the Azure DevOps project the secure file belongs to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			sfraSecureFileId: {
+				Description: "The ID of the secure file to assign the role on. " +
+					"The role is assigned on the secure file library of the project when this is not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			sfraIdentityId: {
+				Description:  "The ID of the user or group to assign the role to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			sfraRole: {
+				Description: "The role to assign. One of `" + strings.Join(secureFileRoles, "`, `") + "` on a secure " +
+					"file, or one of `" + strings.Join(libraryRoles, "`, `") + "` on the secure file library.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(libraryRoles, false),
+			},
+			"id": {
+				Description: "The ID of this resource.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceSecureFileRoleAssignmentCreate(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId, identityId := expandSecureFileRoleAssignment(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	err := setSecureFileRoleAssignment(clients, ctx, scopeId, resourceId, identityId, d.Get(sfraRole).(string))
+	if err != nil {
+		return diag.Errorf("Error assigning role on %s (%s) in Azure DevOps: %+v", scopeId, resourceId, err)
+	}
+
+	d.SetId(secureFileRoleAssignmentId(projectId, secureFileId, identityId))
+
+	return resourceSecureFileRoleAssignmentRead(ctx, d, meta)
+}
+
+func resourceSecureFileRoleAssignmentRead(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId, identityId := expandSecureFileRoleAssignment(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	roleAssignments, err := clients.SecurityRolesClient.GetRoleAssignments(
+		ctx, securityroles.GetRoleAssignmentsArgs{
+			ScopeId:    &scopeId,
+			ResourceId: &resourceId,
+		},
+	)
+	if err != nil {
+		if utils.ResponseWasNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf(
+			"Error looking up role assignments given scope (%v) and resource ID (%v): %v", scopeId, resourceId, err,
+		)
+	}
+
+	role := findAssignedRole(roleAssignments, identityId)
+	if role == "" {
+		d.SetId("")
+		return nil
+	}
+
+	_ = d.Set(sfraRole, role)
+
+	return nil
+}
+
+func resourceSecureFileRoleAssignmentUpdate(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId, identityId := expandSecureFileRoleAssignment(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	if d.HasChange(sfraRole) {
+		err := setSecureFileRoleAssignment(clients, ctx, scopeId, resourceId, identityId, d.Get(sfraRole).(string))
+		if err != nil {
+			return diag.Errorf("Error updating role on %s (%s) in Azure DevOps: %+v", scopeId, resourceId, err)
+		}
+	}
+
+	return resourceSecureFileRoleAssignmentRead(ctx, d, meta)
+}
+
+func resourceSecureFileRoleAssignmentDelete(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId, identityId := expandSecureFileRoleAssignment(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	identityUuid, err := uuid.Parse(identityId)
+	if err != nil {
+		return diag.Errorf("Error parsing the identity ID from the Terraform resource data: %v", err)
+	}
+
+	err = clients.SecurityRolesClient.RemoveRoleAssignments(
+		ctx, securityroles.RemoveRoleAssignmentsArgs{
+			ScopeId:     &scopeId,
+			ResourceId:  &resourceId,
+			IdentityIds: &[]uuid.UUID{identityUuid},
+		},
+	)
+	if err != nil {
+		return diag.Errorf("Error removing role assignment on %s (%s) in Azure DevOps: %+v", scopeId, resourceId, err)
+	}
+
+	return nil
+}
+
+func resourceSecureFileRoleAssignmentImport(
+	_ context.Context, d *schema.ResourceData, _ interface{},
+) ([]*schema.ResourceData, error) {
+	projectId, secureFileId, identityId, err := parseSecureFileRoleAssignmentId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	_ = d.Set(sfraProjectId, projectId)
+	_ = d.Set(sfraSecureFileId, secureFileId)
+	_ = d.Set(sfraIdentityId, identityId)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// validateSecureFileRoleAssignmentRole checks that the role can be assigned on the scope it is assigned on, as roles such
+// as `Creator` only exist on the secure file library.
+func validateSecureFileRoleAssignmentRole(
+	_ context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse,
+) {
+	if !req.RawConfig.IsKnown() || req.RawConfig.IsNull() {
+		return
+	}
+
+	secureFileId := req.RawConfig.GetAttr(sfraSecureFileId)
+	role := req.RawConfig.GetAttr(sfraRole)
+	if !secureFileId.IsKnown() || secureFileId.IsNull() || !role.IsKnown() || role.IsNull() {
+		return
+	}
+	if !slices.Contains(libraryRoles, role.AsString()) || slices.Contains(secureFileRoles, role.AsString()) {
+		return
+	}
+
+	resp.Diagnostics = append(
+		resp.Diagnostics, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid role for a secure file",
+			Detail: fmt.Sprintf(
+				"The %s role can only be assigned on the secure file library, expected one of %s when %q is set.",
+				role.AsString(), strings.Join(secureFileRoles, ", "), sfraSecureFileId,
+			),
+			AttributePath: cty.GetAttrPath(sfraRole),
+		},
+	)
+}
+
+func expandSecureFileRoleAssignment(d *schema.ResourceData) (string, string, string) {
+	return d.Get(sfraProjectId).(string), d.Get(sfraSecureFileId).(string), d.Get(sfraIdentityId).(string)
+}
+
+// secureFileRoleAssignmentScope returns the security role scope and resource ID of a secure file, or of the secure file
+// library of the project when no secure file ID is given.
+func secureFileRoleAssignmentScope(projectId string, secureFileId string) (string, string) {
+	if secureFileId == "" {
+		return libraryRoleScope, projectId + "$0"
+	}
+	return secureFileSecurityRoleScope, projectId + "$" + secureFileId
+}
+
+func setSecureFileRoleAssignment(
+	clients *client.Clients, ctx context.Context, scopeId string, resourceId string, identityId string, role string,
+) error {
+	identityUuid, err := uuid.Parse(identityId)
+	if err != nil {
+		return err
+	}
+
+	_, err = clients.SecurityRolesClient.SetRoleAssignments(
+		ctx, securityroles.SetRoleAssignmentsArgs{
+			ScopeId:    &scopeId,
+			ResourceId: &resourceId,
+			RoleAssignments: &[]securityroles.UserRoleAssignmentRef{
+				{
+					RoleName: &role,
+					UserId:   &identityUuid,
+				},
+			},
+		},
+	)
+	return err
+}
+
+// findAssignedRole returns the name of the role explicitly assigned to the identity, ignoring inherited assignments.
+func findAssignedRole(roleAssignments *[]securityroles.RoleAssignment, identityId string) string {
+	if roleAssignments == nil {
+		return ""
+	}
+	for _, roleAssignment := range *roleAssignments {
+		if roleAssignment.Identity == nil || roleAssignment.Identity.Id == nil ||
+			!strings.EqualFold(*roleAssignment.Identity.Id, identityId) {
+			continue
+		}
+		if roleAssignment.Access == nil || *roleAssignment.Access != securityroles.RoleAccessValues.Assigned {
+			continue
+		}
+		if roleAssignment.Role != nil && roleAssignment.Role.Name != nil {
+			return *roleAssignment.Role.Name
+		}
+	}
+	return ""
+}
+
+func secureFileRoleAssignmentId(projectId string, secureFileId string, identityId string) string {
+	if secureFileId == "" {
+		return fmt.Sprintf("%s/%s", projectId, identityId)
+	}
+	return fmt.Sprintf("%s/%s/%s", projectId, secureFileId, identityId)
+}
+
+func parseSecureFileRoleAssignmentId(id string) (string, string, string, error) {
+	parts := strings.Split(id, "/")
+	for _, part := range parts {
+		if _, err := uuid.Parse(part); err != nil {
+			parts = nil
+			break
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return parts[0], "", parts[1], nil
+	case 3:
+		return parts[0], parts[1], parts[2], nil
+	default:
+		return "", "", "", fmt.Errorf(
+			"unexpected format of ID (%s), expected <project_id>/[<secure_file_id>/]<identity_id>", id,
+		)
+	}
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+func preCheckIdentity(t *testing.T) {
+	identityId := os.Getenv("AZDO_TEST_IDENTITY_ID")
+	if identityId == "" {
+		t.Skipf("AZDO_TEST_IDENTITY_ID not set")
+	}
+}
+
+func TestAccResourceSecureFileRoleAssignment(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	identityId := os.Getenv("AZDO_TEST_IDENTITY_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+				preCheckIdentity(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccResourceSecureFileRoleAssignmentConfig(projectId, fileName, identityId, "Reader"),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file_role_assignment.foo", "role", "Reader"),
+					),
+				},
+				{
+					Config: testAccResourceSecureFileRoleAssignmentConfig(projectId, fileName, identityId, "Administrator"),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file_role_assignment.foo", "role", "Administrator"),
+					),
+				},
+				{
+					ResourceName:      "azdoext_secure_file_role_assignment.foo",
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+			},
+		},
+	)
+}
+
+func TestSecureFileRoleAssignmentScope(t *testing.T) {
+	projectId := "00000000-0000-0000-0000-000000000001"
+	secureFileId := "00000000-0000-0000-0000-000000000002"
+
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+	require.Equal(t, "distributedtask.securefile", scopeId)
+	require.Equal(t, projectId+"$"+secureFileId, resourceId)
+
+	scopeId, resourceId = secureFileRoleAssignmentScope(projectId, "")
+	require.Equal(t, "distributedtask.library", scopeId)
+	require.Equal(t, projectId+"$0", resourceId)
+}
+
+func TestValidateSecureFileRoleAssignmentRole(t *testing.T) {
+	secureFileId := cty.StringVal("00000000-0000-0000-0000-000000000002")
+
+	test := func(secureFileId cty.Value, role string, expectErr bool) func(*testing.T) {
+		return func(t *testing.T) {
+			resp := &schema.ValidateResourceConfigFuncResponse{}
+			validateSecureFileRoleAssignmentRole(
+				context.Background(), schema.ValidateResourceConfigFuncRequest{
+					RawConfig: cty.ObjectVal(
+						map[string]cty.Value{sfraSecureFileId: secureFileId, sfraRole: cty.StringVal(role)},
+					),
+				}, resp,
+			)
+			require.Equal(t, expectErr, resp.Diagnostics.HasError())
+		}
+	}
+
+	for _, role := range libraryRoles {
+		t.Run("library_"+role, test(cty.NullVal(cty.String), role, false))
+	}
+	for _, role := range secureFileRoles {
+		t.Run("secure_file_"+role, test(secureFileId, role, false))
+	}
+	t.Run("secure_file_Creator", test(secureFileId, "Creator", true))
+	t.Run("unknown_secure_file_Creator", test(cty.UnknownVal(cty.String), "Creator", false))
+}
+
+func TestParseSecureFileRoleAssignmentId(t *testing.T) {
+	projectId := "00000000-0000-0000-0000-000000000001"
+	secureFileId := "00000000-0000-0000-0000-000000000002"
+	identityId := "00000000-0000-0000-0000-000000000003"
+
+	test := func(id string, expectedSecureFileId string, expectErr bool) func(*testing.T) {
+		return func(t *testing.T) {
+			actualProjectId, actualSecureFileId, actualIdentityId, err := parseSecureFileRoleAssignmentId(id)
+			if expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, projectId, actualProjectId)
+			require.Equal(t, expectedSecureFileId, actualSecureFileId)
+			require.Equal(t, identityId, actualIdentityId)
+			require.Equal(t, id, secureFileRoleAssignmentId(actualProjectId, actualSecureFileId, actualIdentityId))
+		}
+	}
+
+	t.Run("library", test(projectId+"/"+identityId, "", false))
+	t.Run("secure_file", test(projectId+"/"+secureFileId+"/"+identityId, secureFileId, false))
+	t.Run("missing_identity_id", test(projectId, "", true))
+	t.Run("invalid_identity_id", test(projectId+"/foo", "", true))
+	t.Run("too_many_parts", test(projectId+"/"+secureFileId+"/"+identityId+"/"+identityId, "", true))
+}
+
+func TestFindAssignedRole(t *testing.T) {
+	identityId := "00000000-0000-0000-0000-000000000003"
+	newRoleAssignment := func(
+		identityId string, access securityroles.RoleAccess, role string,
+	) securityroles.RoleAssignment {
+		return securityroles.RoleAssignment{
+			Access:   &access,
+			Identity: &webapi.IdentityRef{Id: utils.NewString(identityId)},
+			Role:     &securityroles.SecurityRole{Name: utils.NewString(role)},
+		}
+	}
+
+	test := func(roleAssignments *[]securityroles.RoleAssignment, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			require.Equal(t, expected, findAssignedRole(roleAssignments, identityId))
+		}
+	}
+
+	t.Run("nil", test(nil, ""))
+	t.Run(
+		"assigned", test(
+			&[]securityroles.RoleAssignment{
+				newRoleAssignment("00000000-0000-0000-0000-000000000004", securityroles.RoleAccessValues.Assigned, "User"),
+				newRoleAssignment(identityId, securityroles.RoleAccessValues.Assigned, "Reader"),
+			}, "Reader",
+		),
+	)
+	t.Run(
+		"inherited", test(
+			&[]securityroles.RoleAssignment{
+				newRoleAssignment(identityId, securityroles.RoleAccessValues.Inherited, "Administrator"),
+			}, "",
+		),
+	)
+}
+
+func testAccResourceSecureFileRoleAssignmentConfig(
+	projectId string, fileName string, identityId string, role string,
+) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name       = %q
+  content    = "Hello World"
+}
+
+resource "azdoext_secure_file_role_assignment" "foo" {
+  project_id     = azdoext_secure_file.foo.project_id
+  secure_file_id = azdoext_secure_file.foo.id
+  identity_id    = %q
+  role           = %q
+}
+`, projectId, fileName, identityId, role,
+	)
+}
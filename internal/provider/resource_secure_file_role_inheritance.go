@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+const (
+	sfriProjectId          = "project_id"
+	sfriSecureFileId       = "secure_file_id"
+	sfriInheritPermissions = "inherit_permissions"
+)
+
+func resourceSecureFileRoleInheritance() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages whether a secure file, or the secure file library of a project, inherits security role " +
+			"assignments from its parent scope within Azure DevOps.\n\n" +
+			"Inheritance is restored when this resource is destroyed. Azure DevOps does not report inheritance " +
+			"directly, so it is read as enabled when any role assignment is inherited from the parent scope. When no " +
+			"role assignments are found at all, the configured value is kept.",
+
+		CreateContext: resourceSecureFileRoleInheritanceCreate,
+		ReadContext:   resourceSecureFileRoleInheritanceRead,
+		UpdateContext: resourceSecureFileRoleInheritanceUpdate,
+		DeleteContext: resourceSecureFileRoleInheritanceDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSecureFileRoleInheritanceImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			sfriProjectId: {
+				Description:  "The ID of the Azure DevOps project the secure file belongs to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			sfriSecureFileId: {
+				Description: "The ID of the secure file to manage inheritance on. " +
+					"Inheritance is managed on the secure file library of the project when this is not set.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IsUUID,
+			},
+			sfriInheritPermissions: {
+				Description: "Whether the secure file or library inherits role assignments from its parent scope.",
+				Type:        schema.TypeBool,
+				Required:    true,
+			},
+			"id": {
+				Description: "The ID of this resource.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceSecureFileRoleInheritanceCreate(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId := expandSecureFileRoleInheritance(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	err := setSecureFileInheritPermissions(
+		clients, ctx, scopeId, resourceId, d.Get(sfriInheritPermissions).(bool),
+	)
+	if err != nil {
+		return diag.Errorf(
+			"Error setting inheritance of role assignments on %s (%s) in Azure DevOps: %+v", scopeId, resourceId, err,
+		)
+	}
+
+	d.SetId(secureFileRoleInheritanceId(projectId, secureFileId))
+
+	return resourceSecureFileRoleInheritanceRead(ctx, d, meta)
+}
+
+func resourceSecureFileRoleInheritanceRead(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId := expandSecureFileRoleInheritance(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	roleAssignments, err := getSecureFileRoleAssignments(clients, ctx, scopeId, resourceId)
+	if err != nil {
+		if utils.ResponseWasNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf(
+			"Error looking up role assignments given scope (%v) and resource ID (%v): %v", scopeId, resourceId, err,
+		)
+	}
+
+	if inheritPermissions, ok := findInheritPermissions(roleAssignments); ok {
+		_ = d.Set(sfriInheritPermissions, inheritPermissions)
+	}
+
+	return nil
+}
+
+func resourceSecureFileRoleInheritanceUpdate(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId := expandSecureFileRoleInheritance(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	if d.HasChange(sfriInheritPermissions) {
+		err := setSecureFileInheritPermissions(
+			clients, ctx, scopeId, resourceId, d.Get(sfriInheritPermissions).(bool),
+		)
+		if err != nil {
+			return diag.Errorf(
+				"Error updating inheritance of role assignments on %s (%s) in Azure DevOps: %+v", scopeId, resourceId,
+				err,
+			)
+		}
+	}
+
+	return resourceSecureFileRoleInheritanceRead(ctx, d, meta)
+}
+
+func resourceSecureFileRoleInheritanceDelete(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) diag.Diagnostics {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId := expandSecureFileRoleInheritance(d)
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+
+	if !d.Get(sfriInheritPermissions).(bool) {
+		err := setSecureFileInheritPermissions(clients, ctx, scopeId, resourceId, true)
+		if err != nil {
+			return diag.Errorf(
+				"Error restoring inheritance of role assignments on %s (%s) in Azure DevOps: %+v", scopeId,
+				resourceId, err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func resourceSecureFileRoleInheritanceImport(
+	ctx context.Context, d *schema.ResourceData, meta interface{},
+) ([]*schema.ResourceData, error) {
+	clients := meta.(*client.Clients)
+
+	projectId, secureFileId, err := parseSecureFileRoleInheritanceId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	scopeId, resourceId := secureFileRoleAssignmentScope(projectId, secureFileId)
+	roleAssignments, err := getSecureFileRoleAssignments(clients, ctx, scopeId, resourceId)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error looking up role assignments given scope (%v) and resource ID (%v): %v", scopeId, resourceId, err,
+		)
+	}
+
+	// Inheritance is enabled by default, so it is assumed to be when it cannot be told from the role assignments
+	inheritPermissions, ok := findInheritPermissions(roleAssignments)
+	if !ok {
+		inheritPermissions = true
+	}
+
+	_ = d.Set(sfriProjectId, projectId)
+	_ = d.Set(sfriSecureFileId, secureFileId)
+	_ = d.Set(sfriInheritPermissions, inheritPermissions)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandSecureFileRoleInheritance(d *schema.ResourceData) (string, string) {
+	return d.Get(sfriProjectId).(string), d.Get(sfriSecureFileId).(string)
+}
+
+func getSecureFileRoleAssignments(
+	clients *client.Clients, ctx context.Context, scopeId string, resourceId string,
+) (*[]securityroles.RoleAssignment, error) {
+	return clients.SecurityRolesClient.GetRoleAssignments(
+		ctx, securityroles.GetRoleAssignmentsArgs{
+			ScopeId:    &scopeId,
+			ResourceId: &resourceId,
+		},
+	)
+}
+
+// findInheritPermissions reports whether role assignments are inherited from the parent scope, which Azure DevOps only
+// reveals through the access of the role assignments. It cannot be told when there are no role assignments at all.
+func findInheritPermissions(roleAssignments *[]securityroles.RoleAssignment) (bool, bool) {
+	if roleAssignments == nil || len(*roleAssignments) == 0 {
+		return false, false
+	}
+	for _, roleAssignment := range *roleAssignments {
+		if roleAssignment.Access != nil && *roleAssignment.Access == securityroles.RoleAccessValues.Inherited {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func setSecureFileInheritPermissions(
+	clients *client.Clients, ctx context.Context, scopeId string, resourceId string, inheritPermissions bool,
+) error {
+	return clients.SecurityRolesClient.SetInheritPermissions(
+		ctx, securityroles.SetInheritPermissionsArgs{
+			ScopeId:            &scopeId,
+			ResourceId:         &resourceId,
+			InheritPermissions: &inheritPermissions,
+		},
+	)
+}
+
+func secureFileRoleInheritanceId(projectId string, secureFileId string) string {
+	if secureFileId == "" {
+		return projectId
+	}
+	return fmt.Sprintf("%s/%s", projectId, secureFileId)
+}
+
+func parseSecureFileRoleInheritanceId(id string) (string, string, error) {
+	parts := strings.Split(id, "/")
+	for _, part := range parts {
+		if _, err := uuid.Parse(part); err != nil {
+			parts = nil
+			break
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return parts[0], "", nil
+	case 2:
+		return parts[0], parts[1], nil
+	default:
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected <project_id>[/<secure_file_id>]", id)
+	}
+}
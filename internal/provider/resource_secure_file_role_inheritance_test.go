@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
+)
+
+func TestAccResourceSecureFileRoleInheritance(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccResourceSecureFileRoleInheritanceConfig(projectId, fileName, false),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							"azdoext_secure_file_role_inheritance.foo", "inherit_permissions", "false",
+						),
+					),
+				},
+				{
+					Config: testAccResourceSecureFileRoleInheritanceConfig(projectId, fileName, true),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(
+							"azdoext_secure_file_role_inheritance.foo", "inherit_permissions", "true",
+						),
+					),
+				},
+				{
+					ResourceName:      "azdoext_secure_file_role_inheritance.foo",
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
+			},
+		},
+	)
+}
+
+func TestParseSecureFileRoleInheritanceId(t *testing.T) {
+	projectId := "00000000-0000-0000-0000-000000000001"
+	secureFileId := "00000000-0000-0000-0000-000000000002"
+
+	test := func(id string, expectedSecureFileId string, expectErr bool) func(*testing.T) {
+		return func(t *testing.T) {
+			actualProjectId, actualSecureFileId, err := parseSecureFileRoleInheritanceId(id)
+			if expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, projectId, actualProjectId)
+			require.Equal(t, expectedSecureFileId, actualSecureFileId)
+			require.Equal(t, id, secureFileRoleInheritanceId(actualProjectId, actualSecureFileId))
+		}
+	}
+
+	t.Run("library", test(projectId, "", false))
+	t.Run("secure_file", test(projectId+"/"+secureFileId, secureFileId, false))
+	t.Run("invalid_secure_file_id", test(projectId+"/foo", "", true))
+	t.Run("too_many_parts", test(projectId+"/"+secureFileId+"/"+secureFileId, "", true))
+}
+
+func TestFindInheritPermissions(t *testing.T) {
+	newRoleAssignment := func(access securityroles.RoleAccess) securityroles.RoleAssignment {
+		return securityroles.RoleAssignment{Access: &access}
+	}
+
+	test := func(
+		roleAssignments *[]securityroles.RoleAssignment, expected bool, expectedOk bool,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			inheritPermissions, ok := findInheritPermissions(roleAssignments)
+			require.Equal(t, expectedOk, ok)
+			require.Equal(t, expected, inheritPermissions)
+		}
+	}
+
+	t.Run("nil", test(nil, false, false))
+	t.Run("empty", test(&[]securityroles.RoleAssignment{}, false, false))
+	t.Run(
+		"inherited", test(
+			&[]securityroles.RoleAssignment{
+				newRoleAssignment(securityroles.RoleAccessValues.Assigned),
+				newRoleAssignment(securityroles.RoleAccessValues.Inherited),
+			}, true, true,
+		),
+	)
+	t.Run(
+		"assigned", test(
+			&[]securityroles.RoleAssignment{newRoleAssignment(securityroles.RoleAccessValues.Assigned)}, false, true,
+		),
+	)
+}
+
+func testAccResourceSecureFileRoleInheritanceConfig(
+	projectId string, fileName string, inheritPermissions bool,
+) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name       = %q
+  content    = "Hello World"
+}
+
+resource "azdoext_secure_file_role_inheritance" "foo" {
+  project_id          = azdoext_secure_file.foo.project_id
+  secure_file_id      = azdoext_secure_file.foo.id
+  inherit_permissions = %v
+}
+`, projectId, fileName, inheritPermissions,
+	)
+}
@@ -1,14 +1,224 @@
 package provider
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	sdkterraform "github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/pipelinepermissions"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/securityroles"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
 )
 
+type fakeTaskAgentClient struct {
+	taskagent.Client
+	content     string
+	calls       *[]string
+	secureFiles []taskagent.SecureFile
+	deleteErr   error
+	getCalls    int
+}
+
+func (c *fakeTaskAgentClient) GetSecureFilesByNames(
+	_ context.Context, args taskagent.GetSecureFilesByNamesArgs,
+) (*[]taskagent.SecureFile, error) {
+	var secureFiles []taskagent.SecureFile
+	for _, name := range *args.SecureFileNames {
+		if strings.Contains(name, ",") {
+			return nil, fmt.Errorf("secure file name (%s) cannot be looked up by name as it contains a comma", name)
+		}
+		for _, secureFile := range c.secureFiles {
+			if strings.EqualFold(*secureFile.Name, name) {
+				secureFiles = append(secureFiles, secureFile)
+			}
+		}
+	}
+	return &secureFiles, nil
+}
+
+func (c *fakeTaskAgentClient) GetSecureFiles(
+	_ context.Context, args taskagent.GetSecureFilesArgs,
+) (*[]taskagent.SecureFile, error) {
+	var secureFiles []taskagent.SecureFile
+	for _, secureFile := range c.secureFiles {
+		if strings.EqualFold(*secureFile.Name, *args.NamePattern) {
+			secureFiles = append(secureFiles, secureFile)
+		}
+	}
+	return &secureFiles, nil
+}
+
+func (c *fakeTaskAgentClient) UploadSecureFile(
+	_ context.Context, args taskagent.UploadSecureFileArgs,
+) (*taskagent.SecureFile, error) {
+	data, err := io.ReadAll(args.Content)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != args.ContentLength {
+		return nil, fmt.Errorf("content length mismatch")
+	}
+	c.content = string(data)
+	*c.calls = append(*c.calls, "upload "+*args.Name)
+	id := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	secureFile := taskagent.SecureFile{Id: &id, Name: args.Name}
+	c.secureFiles = append(c.secureFiles, secureFile)
+	return &secureFile, nil
+}
+
+func (c *fakeTaskAgentClient) DeleteSecureFile(_ context.Context, args taskagent.DeleteSecureFileArgs) error {
+	*c.calls = append(*c.calls, "delete "+args.SecureFileId.String())
+	if c.deleteErr != nil {
+		return c.deleteErr
+	}
+	for i, secureFile := range c.secureFiles {
+		if *secureFile.Id == *args.SecureFileId {
+			c.secureFiles = append(c.secureFiles[:i], c.secureFiles[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (c *fakeTaskAgentClient) GetSecureFile(
+	_ context.Context, args taskagent.GetSecureFileArgs,
+) (*taskagent.SecureFile, error) {
+	c.getCalls++
+	for _, secureFile := range c.secureFiles {
+		if *secureFile.Id == *args.SecureFileId {
+			if args.IncludeDownloadTicket != nil && *args.IncludeDownloadTicket {
+				secureFile.Ticket = utils.NewString("some-ticket")
+			}
+			return &secureFile, nil
+		}
+	}
+	return nil, fmt.Errorf("secure file not found")
+}
+
+func (c *fakeTaskAgentClient) UpdateSecureFile(
+	_ context.Context, args taskagent.UpdateSecureFileArgs,
+) (*taskagent.SecureFile, error) {
+	*c.calls = append(*c.calls, "update "+*args.SecureFile.Name)
+	for i, secureFile := range c.secureFiles {
+		if *secureFile.Id == *args.SecureFileId {
+			c.secureFiles[i].Name = args.SecureFile.Name
+			c.secureFiles[i].Properties = args.SecureFile.Properties
+			return &c.secureFiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("secure file not found")
+}
+
+type fakeBuildClient struct {
+	build.Client
+	calls *[]string
+}
+
+func (c *fakeBuildClient) AuthorizeProjectResources(
+	_ context.Context, args build.AuthorizeProjectResourcesArgs,
+) (*[]build.DefinitionResourceReference, error) {
+	for _, resource := range *args.Resources {
+		*c.calls = append(*c.calls, fmt.Sprintf("authorize %s %v", *resource.Id, *resource.Authorized))
+	}
+	return args.Resources, nil
+}
+
+func (c *fakeBuildClient) GetProjectResources(
+	_ context.Context, _ build.GetProjectResourcesArgs,
+) (*[]build.DefinitionResourceReference, error) {
+	return &[]build.DefinitionResourceReference{}, nil
+}
+
+type fakePipelinePermissionsClient struct {
+	pipelinepermissions.Client
+	permissions map[string]pipelinepermissions.ResourcePipelinePermissions
+	calls       *[]string
+}
+
+func (c *fakePipelinePermissionsClient) GetPipelinePermissionsForResource(
+	_ context.Context, args pipelinepermissions.GetPipelinePermissionsForResourceArgs,
+) (*pipelinepermissions.ResourcePipelinePermissions, error) {
+	*c.calls = append(*c.calls, "get permissions "+*args.ResourceId)
+	permissions := c.permissions[*args.ResourceId]
+	return &permissions, nil
+}
+
+func (c *fakePipelinePermissionsClient) UpdatePipelinePermisionsForResource(
+	_ context.Context, args pipelinepermissions.UpdatePipelinePermisionsForResourceArgs,
+) (*pipelinepermissions.ResourcePipelinePermissions, error) {
+	*c.calls = append(*c.calls, "update permissions "+*args.ResourceId)
+	c.permissions[*args.ResourceId] = *args.ResourceAuthorization
+	return args.ResourceAuthorization, nil
+}
+
+type fakeSecurityRolesClient struct {
+	securityroles.Client
+	roleAssignments map[string][]securityroles.RoleAssignment
+	calls           *[]string
+}
+
+func (c *fakeSecurityRolesClient) GetRoleAssignments(
+	_ context.Context, args securityroles.GetRoleAssignmentsArgs,
+) (*[]securityroles.RoleAssignment, error) {
+	roleAssignments := c.roleAssignments[*args.ResourceId]
+	return &roleAssignments, nil
+}
+
+func (c *fakeSecurityRolesClient) SetRoleAssignments(
+	_ context.Context, args securityroles.SetRoleAssignmentsArgs,
+) (*[]securityroles.RoleAssignment, error) {
+	for _, roleAssignment := range *args.RoleAssignments {
+		*c.calls = append(
+			*c.calls, fmt.Sprintf("assign %s %s %s", *args.ResourceId, *roleAssignment.UserId, *roleAssignment.RoleName),
+		)
+	}
+	return &[]securityroles.RoleAssignment{}, nil
+}
+
+func (c *fakeSecurityRolesClient) SetInheritPermissions(
+	_ context.Context, args securityroles.SetInheritPermissionsArgs,
+) error {
+	*c.calls = append(*c.calls, fmt.Sprintf("inherit %s %v", *args.ResourceId, *args.InheritPermissions))
+	return nil
+}
+
+func (c *fakeTaskAgentClient) DownloadSecureFile(
+	_ context.Context, args taskagent.DownloadSecureFileArgs,
+) (io.ReadCloser, error) {
+	if *args.Ticket != "some-ticket" {
+		return nil, fmt.Errorf("invalid ticket")
+	}
+	return io.NopCloser(strings.NewReader(c.content)), nil
+}
+
 func preCheckProject(t *testing.T) {
 	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
 	if projectId == "" {
@@ -29,7 +239,7 @@ func TestAccResourceSecureFile(t *testing.T) {
 				preCheck(t)
 				preCheckProject(t)
 			},
-			ProviderFactories: providerFactories,
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
 			Steps: []resource.TestStep{
 				{
 					Config: testAccResourceSecureFileConfig(projectId, fileName, content, false, false),
@@ -37,6 +247,8 @@ func TestAccResourceSecureFile(t *testing.T) {
 						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "name", id.String()+".txt"),
 						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "content", contentHash),
 						resource.TestCheckNoResourceAttr("azdoext_secure_file.foo", "properties.foo"),
+						resource.TestCheckResourceAttrSet("azdoext_secure_file.foo", "created_by_descriptor"),
+						resource.TestCheckResourceAttrSet("azdoext_secure_file.foo", "modified_on"),
 					),
 				},
 				{
@@ -71,11 +283,1058 @@ func TestAccResourceSecureFile(t *testing.T) {
 						resource.TestCheckNoResourceAttr("azdoext_secure_file.foo", "properties.foo"),
 					),
 				},
+				{
+					ResourceName:            "azdoext_secure_file.foo",
+					ImportState:             true,
+					ImportStateIdFunc:       testAccSecureFileImportStateIdFunc("azdoext_secure_file.foo"),
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"content", "content_base64", "source"},
+				},
+				{
+					ResourceName:            "azdoext_secure_file.foo",
+					ImportState:             true,
+					ImportStateId:           projectId + "/foo-" + fileName,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"content", "content_base64", "source"},
+				},
+			},
+		},
+	)
+}
+
+func TestDownloadSecureFileContentHashes(t *testing.T) {
+	test := func(content string, expectedContentHash string, expectedContentBase64Hash string) func(*testing.T) {
+		return func(t *testing.T) {
+			clients := &client.Clients{TaskAgentClient: &fakeTaskAgentClient{content: content}}
+			secureFile := &taskagent.SecureFile{Id: &uuid.Nil, Ticket: utils.NewString("some-ticket")}
+
+			contentHash, contentBase64Hash, err := downloadSecureFileContentHashes(
+				clients, context.Background(), utils.NewString("project"), secureFile,
+			)
+			require.NoError(t, err)
+			require.Equal(t, expectedContentHash, contentHash)
+			require.Equal(t, expectedContentBase64Hash, contentBase64Hash)
+			require.Equal(t, secureFileContentHash(content), contentHash)
+		}
+	}
+
+	// SHA256 hashes of "Hello World" and "Hello"
+	helloWorldHash := "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
+	helloHash := "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969"
+
+	t.Run("empty", test("", secureFileContentHash(""), secureFileContentHash("")))
+	t.Run("plain_text", test("Hello World", helloWorldHash, helloWorldHash))
+	t.Run("plain_text_valid_base64", test("SGVsbG8=", helloHash, sha256Hex("SGVsbG8=")))
+	t.Run(
+		"large_plain_text_valid_base64", test(
+			strings.Repeat("SGVsbG8g", 10000), sha256Hex(strings.Repeat("Hello ", 10000)),
+			sha256Hex(strings.Repeat("SGVsbG8g", 10000)),
+		),
+	)
+}
+
+func TestReplaceSecureFileContent(t *testing.T) {
+	var calls []string
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	replacementId := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	pipelinePermissions := pipelinepermissions.ResourcePipelinePermissions{
+		Pipelines: &[]pipelinepermissions.PipelinePermission{
+			{Id: utils.NewInt(42), Authorized: utils.NewBool(true)},
+		},
+	}
+
+	assigned, inherited := securityroles.RoleAccessValues.Assigned, securityroles.RoleAccessValues.Inherited
+	roleAssignment := func(identityId string, role string, access securityroles.RoleAccess) securityroles.RoleAssignment {
+		return securityroles.RoleAssignment{
+			Access:   &access,
+			Identity: &webapi.IdentityRef{Id: utils.NewString(identityId)},
+			Role:     &securityroles.SecurityRole{Name: utils.NewString(role)},
+		}
+	}
+
+	taskAgentClient := &fakeTaskAgentClient{calls: &calls}
+	securityRolesClient := &fakeSecurityRolesClient{
+		roleAssignments: map[string][]securityroles.RoleAssignment{
+			"project$" + existingId.String(): {
+				roleAssignment("00000000-0000-0000-0000-000000000003", "Administrator", assigned),
+				roleAssignment("00000000-0000-0000-0000-000000000004", "User", assigned),
+				roleAssignment("00000000-0000-0000-0000-000000000005", "Reader", inherited),
 			},
 		},
+		calls: &calls,
+	}
+	pipelinePermissionsClient := &fakePipelinePermissionsClient{
+		permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{
+			existingId.String(): pipelinePermissions,
+		},
+		calls: &calls,
+	}
+	clients := &client.Clients{
+		TaskAgentClient:           taskAgentClient,
+		BuildClient:               &fakeBuildClient{calls: &calls},
+		PipelinePermissionsClient: pipelinePermissionsClient,
+		SecurityRolesClient:       securityRolesClient,
+	}
+
+	d := schema.TestResourceDataRaw(
+		t, resourceSecureFile().Schema, map[string]interface{}{
+			sfName:    "foo.txt",
+			sfContent: "Hello World",
+		},
+	)
+	d.SetId(existingId.String())
+
+	secureFileId, err := replaceSecureFileContent(
+		clients, context.Background(), d, utils.NewString("project"), &existingId, expandSecureFile(d),
+	)
+	require.NoError(t, err)
+	require.Equal(t, replacementId, *secureFileId)
+	require.Equal(t, "Hello World", taskAgentClient.content)
+	require.Equal(t, pipelinePermissions, pipelinePermissionsClient.permissions[replacementId.String()])
+
+	// Only the roles assigned on the existing secure file itself are copied, as the rest are inherited anyway
+	require.Len(t, calls, 7)
+	require.Regexp(t, `^upload foo\.txt\.[0-9a-f-]{36}\.tmp$`, calls[0])
+	require.Equal(
+		t, []string{
+			"get permissions " + existingId.String(),
+			"update permissions " + replacementId.String(),
+			"assign project$" + replacementId.String() + " 00000000-0000-0000-0000-000000000003 Administrator",
+			"assign project$" + replacementId.String() + " 00000000-0000-0000-0000-000000000004 User",
+			"delete " + existingId.String(),
+			"authorize " + existingId.String() + " false",
+		}, calls[1:],
+	)
+
+	t.Run("delete_failure", func(t *testing.T) {
+		calls = nil
+		taskAgentClient.deleteErr = fmt.Errorf("secure file is in use")
+
+		_, err := replaceSecureFileContent(
+			clients, context.Background(), d, utils.NewString("project"), &existingId, expandSecureFile(d),
+		)
+		require.ErrorContains(t, err, "secure file is in use")
+
+		// The existing secure file must keep its allow access when it cannot be deleted
+		require.Len(t, calls, 7)
+		require.Equal(
+			t, []string{
+				"get permissions " + existingId.String(),
+				"update permissions " + replacementId.String(),
+				"assign project$" + replacementId.String() + " 00000000-0000-0000-0000-000000000003 Administrator",
+				"assign project$" + replacementId.String() + " 00000000-0000-0000-0000-000000000004 User",
+				"delete " + existingId.String(),
+				"delete " + replacementId.String(),
+			}, calls[1:],
+		)
+	})
+
+	t.Run("not_inherited", func(t *testing.T) {
+		calls = nil
+		taskAgentClient.deleteErr = nil
+		securityRolesClient.roleAssignments["project$"+existingId.String()] = []securityroles.RoleAssignment{
+			roleAssignment("00000000-0000-0000-0000-000000000003", "Administrator", assigned),
+		}
+
+		_, err := replaceSecureFileContent(
+			clients, context.Background(), d, utils.NewString("project"), &existingId, expandSecureFile(d),
+		)
+		require.NoError(t, err)
+		require.Contains(t, calls, "inherit project$"+replacementId.String()+" false")
+	})
+}
+
+func TestCheckSecureFileNameAvailable(t *testing.T) {
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{
+			secureFiles: []taskagent.SecureFile{
+				{Id: &existingId, Name: utils.NewString("signing.p12")},
+			},
+		},
+	}
+
+	test := func(name string, secureFileId string, expectErr bool) func(*testing.T) {
+		return func(t *testing.T) {
+			err := checkSecureFileNameAvailable(clients, context.Background(), "project", name, secureFileId)
+			if !expectErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), existingId.String())
+			require.Contains(t, err.Error(), "terraform import azdoext_secure_file.<name> project/"+existingId.String())
+		}
+	}
+
+	t.Run("available", test("other.p12", "", false))
+	t.Run("create_collision", test("signing.p12", "", true))
+	t.Run("create_collision_different_case", test("SIGNING.p12", "", true))
+	t.Run("rename_collision", test("signing.p12", "00000000-0000-0000-0000-000000000002", true))
+	t.Run("rename_self", test("Signing.p12", existingId.String(), false))
+}
+
+func TestResourceSecureFileCreateOnConflict(t *testing.T) {
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	uploadedId := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	test := func(onConflict string, expectedId uuid.UUID, expectedCalls []string) func(*testing.T) {
+		return func(t *testing.T) {
+			var calls []string
+			taskAgentClient := &fakeTaskAgentClient{
+				calls: &calls,
+				secureFiles: []taskagent.SecureFile{
+					{Id: &existingId, Name: utils.NewString("signing.p12")},
+				},
+			}
+			clients := &client.Clients{
+				TaskAgentClient: taskAgentClient,
+				BuildClient:     &fakeBuildClient{calls: &calls},
+				PipelinePermissionsClient: &fakePipelinePermissionsClient{
+					permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{},
+					calls:       &calls,
+				},
+			}
+
+			d := schema.TestResourceDataRaw(
+				t, resourceSecureFile().Schema, map[string]interface{}{
+					sfProjectId:  "project",
+					sfName:       "signing.p12",
+					sfContent:    "Hello World",
+					sfOnConflict: onConflict,
+				},
+			)
+
+			diags := resourceSecureFileCreate(context.Background(), d, clients)
+			require.Equal(t, expectedCalls, calls)
+			if expectedCalls == nil {
+				require.True(t, diags.HasError())
+				require.Contains(t, diags[0].Summary, "terraform import")
+				return
+			}
+			require.False(t, diags.HasError())
+			require.Equal(t, expectedId.String(), d.Id())
+		}
+	}
+
+	t.Run("fail", test(secureFileOnConflictFail, uuid.Nil, nil))
+	t.Run(
+		"adopt", test(
+			secureFileOnConflictAdopt, existingId, []string{
+				"update signing.p12",
+				"authorize " + existingId.String() + " false",
+				"get permissions " + existingId.String(),
+			},
+		),
+	)
+	t.Run(
+		"replace", test(
+			secureFileOnConflictReplace, uploadedId, []string{
+				"delete " + existingId.String(),
+				"authorize " + existingId.String() + " false",
+				"upload signing.p12",
+				"update signing.p12",
+				"authorize " + uploadedId.String() + " false",
+				"get permissions " + uploadedId.String(),
+			},
+		),
 	)
 }
 
+func TestResourceSecureFileCreateAdoptParseCertificates(t *testing.T) {
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	notAfter := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var calls []string
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{
+			content: newTestCertificatePem(t, notAfter),
+			calls:   &calls,
+			secureFiles: []taskagent.SecureFile{
+				{Id: &existingId, Name: utils.NewString("ca.pem")},
+			},
+		},
+		BuildClient: &fakeBuildClient{calls: &calls},
+		PipelinePermissionsClient: &fakePipelinePermissionsClient{
+			permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{},
+			calls:       &calls,
+		},
+	}
+
+	raw := map[string]interface{}{
+		sfProjectId:         "project",
+		sfName:              "ca.pem",
+		sfContent:           "Hello World",
+		sfOnConflict:        secureFileOnConflictAdopt,
+		sfParseCertificates: true,
+	}
+
+	// The certificates are only known once it is known whether an existing secure file is adopted
+	_, diff := testSecureFileDiff(t, clients, "", map[string]string{}, raw)
+	require.True(t, diff.Attributes[sfCertificate+".#"].NewComputed)
+
+	d := testSecureFileResourceData(t, clients, "", map[string]string{}, raw)
+	diags := resourceSecureFileCreate(context.Background(), d, clients)
+	require.False(t, diags.HasError(), "%v", diags)
+	require.Equal(t, existingId.String(), d.Id())
+
+	parsedCertificates := d.Get(sfCertificate).([]interface{})
+	require.Len(t, parsedCertificates, 1)
+	require.Equal(t, "CN=test", parsedCertificates[0].(map[string]interface{})[certSubject])
+	require.Equal(t, "2030-01-02T03:04:05Z", parsedCertificates[0].(map[string]interface{})[certNotAfter])
+}
+
+// TestResourceSecureFileCertificateExpiryWarnings checks that expiring certificates are warned about when reading the
+// secure file, which happens once per plan while refreshing.
+func TestResourceSecureFileCertificateExpiryWarnings(t *testing.T) {
+	var calls []string
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{calls: &calls},
+		BuildClient:     &fakeBuildClient{calls: &calls},
+		PipelinePermissionsClient: &fakePipelinePermissionsClient{
+			permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{},
+			calls:       &calls,
+		},
+	}
+
+	raw := map[string]interface{}{
+		sfProjectId:         "project",
+		sfName:              "ca.pem",
+		sfContent:           newTestCertificatePem(t, time.Now().AddDate(0, 0, 10)),
+		sfParseCertificates: true,
+	}
+
+	d := testSecureFileResourceData(t, clients, "", map[string]string{}, raw)
+	diags := resourceSecureFileCreate(context.Background(), d, clients)
+	require.False(t, diags.HasError(), "%v", diags)
+	require.Len(t, diags, 1)
+	require.Equal(t, diag.Warning, diags[0].Severity)
+	require.Equal(t, "Secure file contains an expiring certificate", diags[0].Summary)
+
+	require.NoError(t, d.Set(sfCertificateExpiryWarningDays, 7))
+	diags = resourceSecureFileRead(context.Background(), d, clients)
+	require.Empty(t, diags)
+}
+
+// testSecureFileResourceData plans the raw configuration against the prior state, as for an update.
+func testSecureFileResourceData(
+	t *testing.T, clients *client.Clients, id string, state map[string]string, raw map[string]interface{},
+) *schema.ResourceData {
+	instanceState, diff := testSecureFileDiff(t, clients, id, state, raw)
+
+	d, err := schema.InternalMap(resourceSecureFile().Schema).Data(instanceState, diff)
+	require.NoError(t, err)
+	return d
+}
+
+// testSecureFileDiff plans the raw configuration against the prior state.
+func testSecureFileDiff(
+	t *testing.T, clients *client.Clients, id string, state map[string]string, raw map[string]interface{},
+) (*sdkterraform.InstanceState, *sdkterraform.InstanceDiff) {
+	r := resourceSecureFile()
+
+	rawConfig, err := json.Marshal(raw)
+	require.NoError(t, err)
+	config, err := ctyjson.Unmarshal(rawConfig, r.CoreConfigSchema().ImpliedType())
+	require.NoError(t, err)
+
+	instanceState := &sdkterraform.InstanceState{ID: id, Attributes: state, RawConfig: config}
+	diff, err := r.Diff(context.Background(), instanceState, sdkterraform.NewResourceConfigRaw(raw), clients)
+	require.NoError(t, err)
+	return instanceState, diff
+}
+
+// testSecureFileState is the state of a secure file with the given content hash and otherwise default attributes.
+func testSecureFileState(secureFileId uuid.UUID, content string) map[string]string {
+	return map[string]string{
+		"id":                           secureFileId.String(),
+		sfProjectId:                    "project",
+		sfName:                         "hello_world.txt",
+		sfContent:                      content,
+		sfContentBase64:                content,
+		sfSource:                       content,
+		sfDetectDrift:                  "true",
+		sfOnConflict:                   secureFileOnConflictFail,
+		sfCertificateExpiryWarningDays: "30",
+		sfAllowAccess:                  "false",
+		sfParseCertificates:            "false",
+		sfPipelineIds + ".#":           "0",
+		sfProperties + ".%":            "0",
+		sfCertificate + ".#":           "0",
+	}
+}
+
+func TestResourceSecureFileUpdateAdopted(t *testing.T) {
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	state := testSecureFileState(existingId, secureFileContentAdopted)
+
+	for _, tc := range []struct {
+		name          string
+		remoteContent string
+		expectedCalls []string
+	}{
+		{
+			name:          "matching_content",
+			remoteContent: "Hello World",
+			expectedCalls: []string{
+				"update hello_world.txt",
+				"authorize " + existingId.String() + " false",
+				"get permissions " + existingId.String(),
+			},
+		},
+		{
+			name:          "different_content",
+			remoteContent: "Goodbye World",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			taskAgentClient := &fakeTaskAgentClient{
+				content: tc.remoteContent,
+				calls:   &calls,
+				secureFiles: []taskagent.SecureFile{
+					{Id: &existingId, Name: utils.NewString("hello_world.txt")},
+				},
+			}
+			clients := &client.Clients{
+				TaskAgentClient: taskAgentClient,
+				BuildClient:     &fakeBuildClient{calls: &calls},
+				PipelinePermissionsClient: &fakePipelinePermissionsClient{
+					permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{},
+					calls:       &calls,
+				},
+				SecurityRolesClient: &fakeSecurityRolesClient{calls: &calls},
+			}
+
+			d := testSecureFileResourceData(
+				t, clients, existingId.String(), state, map[string]interface{}{
+					sfProjectId: "project",
+					sfName:      "hello_world.txt",
+					sfContent:   "Hello World",
+				},
+			)
+			require.True(t, d.HasChange(sfContent))
+
+			diags := resourceSecureFileUpdate(context.Background(), d, clients)
+			require.False(t, diags.HasError(), "%v", diags)
+			require.Equal(t, secureFileContentHash("Hello World"), d.State().Attributes[sfContent])
+			require.Equal(t, "Hello World", taskAgentClient.content)
+
+			if tc.expectedCalls != nil {
+				require.Equal(t, existingId.String(), d.Id())
+				require.Equal(t, tc.expectedCalls, calls)
+				return
+			}
+			require.NotEqual(t, existingId.String(), d.Id())
+			require.Regexp(t, `^upload hello_world\.txt\.[0-9a-f-]{36}\.tmp$`, calls[0])
+		})
+	}
+}
+
+func TestResourceSecureFileImport(t *testing.T) {
+	projectId := "00000000-0000-0000-0000-0000000000ff"
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{
+			secureFiles: []taskagent.SecureFile{
+				{Id: &existingId, Name: utils.NewString("hello_world.txt")},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		id   string
+	}{
+		{name: "id", id: projectId + "/" + existingId.String()},
+		{name: "name", id: projectId + "/HELLO_WORLD.TXT"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := resourceSecureFile().TestResourceData()
+			d.SetId(tc.id)
+
+			imported, err := resourceSecureFileImport(context.Background(), d, clients)
+			require.NoError(t, err)
+			require.Len(t, imported, 1)
+			require.Equal(t, existingId.String(), imported[0].Id())
+			require.Equal(t, projectId, imported[0].Get(sfProjectId))
+			require.Equal(t, "hello_world.txt", imported[0].Get(sfName))
+			require.Equal(t, secureFileContentAdopted, imported[0].Get(sfContent))
+		})
+	}
+
+	for _, tc := range []struct {
+		name        string
+		id          string
+		expectedErr string
+	}{
+		{
+			name:        "name_not_found",
+			id:          projectId + "/goodbye_world.txt",
+			expectedErr: "secure file with name (goodbye_world.txt) not found",
+		},
+		{
+			name:        "invalid_id",
+			id:          existingId.String(),
+			expectedErr: "expected <project_id_or_name>/<secure_file_id_or_name>",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := resourceSecureFile().TestResourceData()
+			d.SetId(tc.id)
+
+			_, err := resourceSecureFileImport(context.Background(), d, clients)
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestResourceSecureFileRevokePipelines(t *testing.T) {
+	existingId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	state := testSecureFileState(existingId, secureFileContentHash("Hello World"))
+	state[sfContentBase64] = secureFileContentHash("")
+	state[sfSource] = ""
+	state[sfPipelineIds+".#"] = "1"
+	state[fmt.Sprintf("%s.%d", sfPipelineIds, schema.HashSchema(&schema.Schema{Type: schema.TypeInt})(42))] = "42"
+
+	var calls []string
+	pipelinePermissionsClient := &fakePipelinePermissionsClient{
+		permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{
+			existingId.String(): {
+				Pipelines: &[]pipelinepermissions.PipelinePermission{
+					{Id: utils.NewInt(42), Authorized: utils.NewBool(true)},
+				},
+			},
+		},
+		calls: &calls,
+	}
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{
+			calls: &calls,
+			secureFiles: []taskagent.SecureFile{
+				{Id: &existingId, Name: utils.NewString("hello_world.txt")},
+			},
+		},
+		BuildClient:               &fakeBuildClient{calls: &calls},
+		PipelinePermissionsClient: pipelinePermissionsClient,
+	}
+
+	t.Run("not_configured", func(t *testing.T) {
+		d := testSecureFileResourceData(
+			t, clients, existingId.String(), state, map[string]interface{}{
+				sfProjectId: "project",
+				sfName:      "hello_world.txt",
+				sfContent:   "Hello World",
+			},
+		)
+		require.False(t, d.HasChange(sfPipelineIds))
+	})
+
+	t.Run("configured_empty", func(t *testing.T) {
+		d := testSecureFileResourceData(
+			t, clients, existingId.String(), state, map[string]interface{}{
+				sfProjectId:   "project",
+				sfName:        "hello_world.txt",
+				sfContent:     "Hello World",
+				sfPipelineIds: []interface{}{},
+			},
+		)
+		require.True(t, d.HasChange(sfPipelineIds))
+
+		diags := resourceSecureFileUpdate(context.Background(), d, clients)
+		require.False(t, diags.HasError(), "%v", diags)
+		require.Contains(t, calls, "update permissions "+existingId.String())
+		permissions := pipelinePermissionsClient.permissions[existingId.String()]
+		require.Empty(t, authorizedPipelineIds(&permissions))
+		require.Equal(t, 0, d.Get(sfPipelineIds).(*schema.Set).Len())
+	})
+
+	t.Run("adopt_configured_empty", func(t *testing.T) {
+		calls = nil
+		pipelinePermissionsClient.permissions[existingId.String()] = pipelinepermissions.ResourcePipelinePermissions{
+			Pipelines: &[]pipelinepermissions.PipelinePermission{
+				{Id: utils.NewInt(42), Authorized: utils.NewBool(true)},
+			},
+		}
+
+		d := testSecureFileResourceData(
+			t, clients, "", map[string]string{}, map[string]interface{}{
+				sfProjectId:   "project",
+				sfName:        "hello_world.txt",
+				sfContent:     "Hello World",
+				sfOnConflict:  secureFileOnConflictAdopt,
+				sfPipelineIds: []interface{}{},
+			},
+		)
+
+		diags := resourceSecureFileCreate(context.Background(), d, clients)
+		require.False(t, diags.HasError(), "%v", diags)
+		require.Equal(t, existingId.String(), d.Id())
+		require.Contains(t, calls, "update permissions "+existingId.String())
+		permissions := pipelinePermissionsClient.permissions[existingId.String()]
+		require.Empty(t, authorizedPipelineIds(&permissions))
+		require.Equal(t, 0, d.Get(sfPipelineIds).(*schema.Set).Len())
+	})
+}
+
+// hangingTaskAgentClient never finishes uploading, as when Azure DevOps throttles the organisation.
+type hangingTaskAgentClient struct {
+	fakeTaskAgentClient
+}
+
+func (c *hangingTaskAgentClient) UploadSecureFile(
+	ctx context.Context, _ taskagent.UploadSecureFileArgs,
+) (*taskagent.SecureFile, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestResourceSecureFileTimeoutDiagnostics(t *testing.T) {
+	var calls []string
+	clients := &client.Clients{
+		TaskAgentClient: &hangingTaskAgentClient{fakeTaskAgentClient{calls: &calls}},
+	}
+
+	d := schema.TestResourceDataRaw(
+		t, resourceSecureFile().Schema, map[string]interface{}{
+			sfProjectId: "project",
+			sfName:      "hello_world.txt",
+			sfContent:   "Hello World",
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	diags := resourceSecureFile().CreateContext(ctx, d, clients)
+	require.Len(t, diags, 2)
+	require.Equal(t, "Timed out creating secure file", diags[0].Summary)
+	require.Contains(t, diags[0].Detail, "within the create timeout")
+	require.Contains(t, diags[0].Detail, "timeouts {")
+	require.Contains(t, diags[1].Summary, context.DeadlineExceeded.Error())
+
+	t.Run(
+		"other_errors", func(t *testing.T) {
+			diags := withTimeoutDiagnostics(
+				schema.TimeoutRead, "reading",
+				func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+					return diag.Errorf("Error looking up secure file")
+				},
+			)(context.Background(), d, clients)
+			require.Len(t, diags, 1)
+			require.Equal(t, "Error looking up secure file", diags[0].Summary)
+		},
+	)
+}
+
+func TestExpandPipelinePermissions(t *testing.T) {
+	test := func(oldPipelineIds []interface{}, newPipelineIds []interface{}, expected map[int]bool) func(*testing.T) {
+		return func(t *testing.T) {
+			pipelinePermissions := expandPipelinePermissions(
+				schema.NewSet(schema.HashInt, oldPipelineIds), schema.NewSet(schema.HashInt, newPipelineIds),
+			)
+
+			actual := map[int]bool{}
+			for _, pipelinePermission := range pipelinePermissions {
+				actual[*pipelinePermission.Id] = *pipelinePermission.Authorized
+			}
+			require.Equal(t, expected, actual)
+		}
+	}
+
+	t.Run("unchanged", test([]interface{}{1, 2}, []interface{}{1, 2}, map[int]bool{}))
+	t.Run("added", test([]interface{}{1}, []interface{}{1, 2, 3}, map[int]bool{2: true, 3: true}))
+	t.Run("removed", test([]interface{}{1, 2, 3}, []interface{}{2}, map[int]bool{1: false, 3: false}))
+	t.Run("replaced", test([]interface{}{1}, []interface{}{2}, map[int]bool{1: false, 2: true}))
+}
+
+func TestFlattenPipelinePermissions(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceSecureFile().Schema, map[string]interface{}{})
+
+	flattenPipelinePermissions(
+		d, &pipelinepermissions.ResourcePipelinePermissions{
+			Pipelines: &[]pipelinepermissions.PipelinePermission{
+				{Id: utils.NewInt(1), Authorized: utils.NewBool(true)},
+				{Id: utils.NewInt(2), Authorized: utils.NewBool(false)},
+				{Id: utils.NewInt(3), Authorized: utils.NewBool(true)},
+			},
+		},
+	)
+
+	require.ElementsMatch(t, []interface{}{1, 3}, d.Get(sfPipelineIds).(*schema.Set).List())
+}
+
+func TestFlattenSecureFileMetadata(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceSecureFile().Schema, map[string]interface{}{})
+	createdOn := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	flattenSecureFileMetadata(
+		d, &taskagent.SecureFile{
+			CreatedBy: &webapi.IdentityRef{
+				DisplayName: utils.NewString("Jane Doe"),
+				Descriptor:  utils.NewString("aad.ZmFrZQ"),
+			},
+			CreatedOn: &azuredevops.Time{Time: createdOn},
+		},
+	)
+
+	require.Equal(t, "Jane Doe", d.Get(sfCreatedBy))
+	require.Equal(t, "aad.ZmFrZQ", d.Get(sfCreatedByDescriptor))
+	require.Equal(t, "2022-01-02T03:04:05Z", d.Get(sfCreatedOn))
+	require.Equal(t, "", d.Get(sfModifiedBy))
+	require.Equal(t, "", d.Get(sfModifiedByDescriptor))
+	require.Equal(t, "", d.Get(sfModifiedOn))
+}
+
+func TestFlattenSecureFileContentHashes(t *testing.T) {
+	emptyHash := secureFileContentHash("")
+
+	test := func(
+		content string, contentBase64 string, source string,
+		expectedContent string, expectedContentBase64 string, expectedSource string,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceSecureFile().Schema, map[string]interface{}{})
+			_ = d.Set(sfContent, content)
+			_ = d.Set(sfContentBase64, contentBase64)
+			_ = d.Set(sfSource, source)
+
+			flattenSecureFileContentHashes(d, "content-hash", "content-base64-hash")
+
+			require.Equal(t, expectedContent, d.Get(sfContent))
+			require.Equal(t, expectedContentBase64, d.Get(sfContentBase64))
+			require.Equal(t, expectedSource, d.Get(sfSource))
+		}
+	}
+
+	t.Run("content_unchanged", test("content-hash", emptyHash, "", "content-hash", emptyHash, ""))
+	t.Run("content_drifted", test("old-hash", emptyHash, "", "content-hash", emptyHash, ""))
+	t.Run(
+		"content_base64_unchanged",
+		test(emptyHash, "content-base64-hash", "", emptyHash, "content-base64-hash", ""),
+	)
+	t.Run("content_base64_drifted", test(emptyHash, "old-hash", "", emptyHash, "content-base64-hash", ""))
+	t.Run(
+		"source_unchanged",
+		test(emptyHash, emptyHash, "content-base64-hash", emptyHash, emptyHash, "content-base64-hash"),
+	)
+	t.Run("source_drifted", test(emptyHash, emptyHash, "old-hash", emptyHash, emptyHash, "content-base64-hash"))
+	t.Run("empty_drifted", test(emptyHash, emptyHash, "", emptyHash, "content-base64-hash", ""))
+	t.Run(
+		"adopted", test(
+			secureFileContentAdopted, secureFileContentAdopted, secureFileContentAdopted,
+			secureFileContentAdopted, secureFileContentAdopted, secureFileContentAdopted,
+		),
+	)
+}
+
+func TestOpenSecureFileContent(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "hello_world.txt")
+	require.NoError(t, os.WriteFile(source, []byte("Hello World"), 0600))
+
+	test := func(raw map[string]interface{}, expected string) func(*testing.T) {
+		return func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceSecureFile().Schema, raw)
+
+			content, err := openSecureFileContent(d)
+			require.NoError(t, err)
+			defer content.Close()
+
+			data, err := io.ReadAll(content)
+			require.NoError(t, err)
+			require.Equal(t, expected, string(data))
+			require.Equal(t, int64(len(expected)), content.length)
+		}
+	}
+
+	t.Run("empty", test(map[string]interface{}{}, ""))
+	t.Run("content", test(map[string]interface{}{sfContent: "Hello World"}, "Hello World"))
+	t.Run("content_base64", test(map[string]interface{}{sfContentBase64: "SGVsbG8gV29ybGQ="}, "Hello World"))
+	t.Run("source", test(map[string]interface{}{sfSource: source}, "Hello World"))
+	t.Run(
+		"source_hash", func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceSecureFile().Schema, map[string]interface{}{sfSource: source})
+
+			content, err := openSecureFileContent(d)
+			require.NoError(t, err)
+			defer content.Close()
+
+			_, err = io.Copy(io.Discard, content)
+			require.NoError(t, err)
+			require.Equal(t, sha256Hex("Hello World"), hex.EncodeToString(content.sourceHash.Sum(nil)))
+		},
+	)
+	t.Run(
+		"missing_source", func(t *testing.T) {
+			d := schema.TestResourceDataRaw(
+				t, resourceSecureFile().Schema, map[string]interface{}{
+					sfSource: filepath.Join(t.TempDir(), "missing.txt"),
+				},
+			)
+
+			_, err := openSecureFileContent(d)
+			require.ErrorIs(t, err, os.ErrNotExist)
+		},
+	)
+}
+
+func TestWriteOnlySecureFileContent(t *testing.T) {
+	test := func(contentWo cty.Value, contentBase64Wo cty.Value, expected string, expectedOk bool) func(*testing.T) {
+		return func(t *testing.T) {
+			data, ok, err := writeOnlySecureFileContent(
+				cty.ObjectVal(
+					map[string]cty.Value{
+						sfContentWo:       contentWo,
+						sfContentBase64Wo: contentBase64Wo,
+					},
+				),
+			)
+			require.NoError(t, err)
+			require.Equal(t, expectedOk, ok)
+			require.Equal(t, expected, string(data))
+		}
+	}
+
+	t.Run("content_wo", test(cty.StringVal("Hello World"), cty.NullVal(cty.String), "Hello World", true))
+	t.Run("content_wo_empty", test(cty.StringVal(""), cty.NullVal(cty.String), "", true))
+	t.Run(
+		"content_base64_wo", test(cty.NullVal(cty.String), cty.StringVal("SGVsbG8gV29ybGQ="), "Hello World", true),
+	)
+	t.Run("unset", test(cty.NullVal(cty.String), cty.NullVal(cty.String), "", false))
+}
+
+func TestValidateSecureFileWriteOnlyContent(t *testing.T) {
+	test := func(contentWo cty.Value, contentBase64Wo cty.Value, version cty.Value, expectErr bool) func(*testing.T) {
+		return func(t *testing.T) {
+			resp := &schema.ValidateResourceConfigFuncResponse{}
+			validateSecureFileWriteOnlyContent(
+				context.Background(), schema.ValidateResourceConfigFuncRequest{
+					RawConfig: cty.ObjectVal(
+						map[string]cty.Value{
+							sfContentWo:        contentWo,
+							sfContentBase64Wo:  contentBase64Wo,
+							sfContentWoVersion: version,
+						},
+					),
+				}, resp,
+			)
+			require.Equal(t, expectErr, resp.Diagnostics.HasError())
+		}
+	}
+
+	t.Run("content_wo", test(cty.StringVal("Hello World"), cty.NullVal(cty.String), cty.NumberIntVal(1), false))
+	t.Run(
+		"content_base64_wo",
+		test(cty.NullVal(cty.String), cty.StringVal("SGVsbG8gV29ybGQ="), cty.NumberIntVal(1), false),
+	)
+	t.Run("missing_content", test(cty.NullVal(cty.String), cty.NullVal(cty.String), cty.NumberIntVal(1), true))
+	t.Run("unset", test(cty.NullVal(cty.String), cty.NullVal(cty.String), cty.NullVal(cty.Number), false))
+}
+
+func TestValidateSecureFileSource(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "hello_world.txt")
+	require.NoError(t, os.WriteFile(source, []byte("Hello World"), 0600))
+
+	test := func(source cty.Value, expectedSeverity diag.Severity, expectDiags bool) func(*testing.T) {
+		return func(t *testing.T) {
+			resp := &schema.ValidateResourceConfigFuncResponse{}
+			validateSecureFileSource(
+				context.Background(), schema.ValidateResourceConfigFuncRequest{
+					RawConfig: cty.ObjectVal(map[string]cty.Value{sfSource: source}),
+				}, resp,
+			)
+			if !expectDiags {
+				require.Empty(t, resp.Diagnostics)
+				return
+			}
+			require.Len(t, resp.Diagnostics, 1)
+			require.Equal(t, expectedSeverity, resp.Diagnostics[0].Severity)
+		}
+	}
+
+	t.Run("readable", test(cty.StringVal(source), diag.Error, false))
+	t.Run("unset", test(cty.NullVal(cty.String), diag.Error, false))
+	t.Run("unknown", test(cty.UnknownVal(cty.String), diag.Error, false))
+	// A missing source file may still be created while applying, so it only warns
+	t.Run("missing", test(cty.StringVal(filepath.Join(t.TempDir(), "missing.txt")), diag.Warning, true))
+	t.Run("directory", test(cty.StringVal(t.TempDir()), diag.Error, true))
+}
+
+func TestResourceSecureFileCertificatePassword(t *testing.T) {
+	block, _ := pem.Decode([]byte(newTestCertificatePem(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC))))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	trustStore, err := pkcs12.Modern.EncodeTrustStore([]*x509.Certificate{certificate}, "secret")
+	require.NoError(t, err)
+
+	var calls []string
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{calls: &calls},
+		BuildClient:     &fakeBuildClient{calls: &calls},
+		PipelinePermissionsClient: &fakePipelinePermissionsClient{
+			permissions: map[string]pipelinepermissions.ResourcePipelinePermissions{},
+			calls:       &calls,
+		},
+	}
+
+	raw := map[string]interface{}{
+		sfProjectId:           "project",
+		sfName:                "signing.p12",
+		sfContentBase64:       base64.StdEncoding.EncodeToString(trustStore),
+		sfParseCertificates:   true,
+		sfCertificatePassword: "secret",
+	}
+
+	// The password is used to parse the certificates, but only its hash is planned and stored in state
+	_, diff := testSecureFileDiff(t, clients, "", map[string]string{}, raw)
+	require.Equal(t, sha256Hex("secret"), diff.Attributes[sfCertificatePassword].New)
+
+	d := testSecureFileResourceData(t, clients, "", map[string]string{}, raw)
+	diags := resourceSecureFileCreate(context.Background(), d, clients)
+	require.False(t, diags.HasError(), "%v", diags)
+	require.Len(t, d.Get(sfCertificate).([]interface{}), 1)
+	require.Equal(t, sha256Hex("secret"), d.State().Attributes[sfCertificatePassword])
+}
+
+func TestSecureFileSourceHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello_world.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Hello World"), 0600))
+
+	require.Equal(t, sha256Hex("Hello World"), secureFileSourceHash(path))
+	require.Equal(t, secureFileSourceUnreadable, secureFileSourceHash(filepath.Join(t.TempDir(), "missing.txt")))
+	require.Equal(t, "", secureFileSourceHash(""))
+}
+
+func sha256Hex(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestAccResourceSecureFile_source(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+	source := filepath.Join(t.TempDir(), fileName)
+
+	writeSource := func(content string) func() {
+		return func() {
+			require.NoError(t, os.WriteFile(source, []byte(content), 0600))
+		}
+	}
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					PreConfig: writeSource("Hello World"),
+					Config:    testAccResourceSecureFileConfigWithSource(projectId, fileName, source),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "name", fileName),
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "source", sha256Hex("Hello World")),
+					),
+				},
+				{
+					PreConfig: writeSource("Goodbye World"),
+					Config:    testAccResourceSecureFileConfigWithSource(projectId, fileName, source),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "name", fileName),
+						resource.TestCheckResourceAttr(
+							"azdoext_secure_file.foo", "source", sha256Hex("Goodbye World"),
+						),
+					),
+				},
+			},
+		},
+	)
+}
+
+func TestAccResourceSecureFile_writeOnly(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.txt", id)
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+				tfversion.SkipBelow(tfversion.Version1_11_0),
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccResourceSecureFileConfigWithWriteOnly(projectId, fileName, "Hello World", 1),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "name", fileName),
+						resource.TestCheckNoResourceAttr("azdoext_secure_file.foo", "content_wo"),
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "content", sha256Hex("")),
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "content_wo_version", "1"),
+					),
+				},
+				{
+					// Changing only the write-only content cannot be detected, so nothing is uploaded
+					Config:   testAccResourceSecureFileConfigWithWriteOnly(projectId, fileName, "Goodbye", 1),
+					PlanOnly: true,
+				},
+				{
+					Config: testAccResourceSecureFileConfigWithWriteOnly(projectId, fileName, "Goodbye", 2),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "name", fileName),
+						resource.TestCheckNoResourceAttr("azdoext_secure_file.foo", "content_wo"),
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "content_wo_version", "2"),
+					),
+				},
+			},
+		},
+	)
+}
+
+func TestAccResourceSecureFile_certificates(t *testing.T) {
+	projectId := os.Getenv("AZDO_TEST_PROJECT_ID")
+	id, _ := uuid.NewRandom()
+	fileName := fmt.Sprintf("%s.pem", id)
+	certificatePem := newTestCertificatePem(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	resource.UnitTest(
+		t, resource.TestCase{
+			PreCheck: func() {
+				preCheck(t)
+				preCheckProject(t)
+			},
+			ProtoV5ProviderFactories: protoV5ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: testAccResourceSecureFileConfigWithCertificates(projectId, fileName, certificatePem),
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "certificate.#", "1"),
+						resource.TestCheckResourceAttr("azdoext_secure_file.foo", "certificate.0.subject", "CN=test"),
+						resource.TestCheckResourceAttr(
+							"azdoext_secure_file.foo", "certificate.0.not_after", "2030-01-02T03:04:05Z",
+						),
+					),
+				},
+			},
+		},
+	)
+}
+
+func testAccSecureFileImportStateIdFunc(resourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource not found: %s", resourceName)
+		}
+		return fmt.Sprintf("%s/%s", rs.Primary.Attributes["project_id"], rs.Primary.ID), nil
+	}
+}
+
 func testAccResourceSecureFileConfig(
 	projectId string, fileName string, content string, base64Encoded bool, allowAccess bool,
 ) string {
@@ -120,3 +1379,43 @@ resource "azdoext_secure_file" "foo" {
 `, projectId, fileName, content, allowAccess,
 	)
 }
+
+func testAccResourceSecureFileConfigWithSource(projectId string, fileName string, source string) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name = %q
+  source = %q
+}
+`, projectId, fileName, source,
+	)
+}
+
+func testAccResourceSecureFileConfigWithWriteOnly(
+	projectId string, fileName string, content string, contentVersion int,
+) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name = %q
+  content_wo = %q
+  content_wo_version = %d
+}
+`, projectId, fileName, content, contentVersion,
+	)
+}
+
+func testAccResourceSecureFileConfigWithCertificates(projectId string, fileName string, content string) string {
+	return fmt.Sprintf(
+		`
+resource "azdoext_secure_file" "foo" {
+  project_id = %q
+  name = %q
+  content = %q
+  parse_certificates = true
+}
+`, projectId, fileName, content,
+	)
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/certificates"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+)
+
+const defaultCertificateExpiryWarningDays = 30
+
+const (
+	certType       = "type"
+	certSubject    = "subject"
+	certIssuer     = "issuer"
+	certThumbprint = "thumbprint"
+	certNotBefore  = "not_before"
+	certNotAfter   = "not_after"
+	certTeamId     = "team_id"
+)
+
+func secureFileCertificateResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			certType: {
+				Description: "The type of the entry, either `" + certificates.TypeCertificate + "` or `" +
+					certificates.TypeProvisioningProfile + "`.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			certSubject: {
+				Description: "The subject of the certificate, or the name of the provisioning profile.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			certIssuer: {
+				Description: "The issuer of the certificate, or the team name of the provisioning profile.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			certThumbprint: {
+				Description: "The SHA-1 thumbprint of the certificate, or the UUID of the provisioning profile.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			certNotBefore: {
+				Description: "The timestamp from which the certificate or provisioning profile is valid.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			certNotAfter: {
+				Description: "The timestamp at which the certificate or provisioning profile expires.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			certTeamId: {
+				Description: "The Apple developer team ID of the provisioning profile and the certificates embedded " +
+					"within it.",
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// customizeSecureFileCertificates parses the configured content while planning so that the certificates it contains
+// are known before applying, unless an existing secure file may be adopted instead. The content attributes only hold
+// hashes once planned, so the content is read from the raw configuration instead.
+func customizeSecureFileCertificates(d *schema.ResourceDiff) error {
+	if !d.Get(sfParseCertificates).(bool) {
+		if len(d.Get(sfCertificate).([]interface{})) > 0 {
+			return d.SetNew(sfCertificate, []interface{}{})
+		}
+		return nil
+	}
+
+	// An existing secure file may be adopted in place of uploading the configured content
+	if d.Id() == "" && d.Get(sfOnConflict).(string) == secureFileOnConflictAdopt {
+		return d.SetNewComputed(sfCertificate)
+	}
+
+	if d.Id() != "" && !d.HasChanges(sfName, sfCertificatePassword, sfParseCertificates) &&
+		!d.HasChanges(secureFileContentKeys...) {
+		return nil
+	}
+
+	flattenedCertificates, ok, err := configuredSecureFileCertificates(d.GetRawConfig())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return d.SetNewComputed(sfCertificate)
+	}
+
+	return d.SetNew(sfCertificate, flattenedCertificates)
+}
+
+// configuredSecureFileCertificates parses the certificates contained in the content in the raw configuration, or
+// returns false when they cannot be known until applying.
+func configuredSecureFileCertificates(config cty.Value) ([]interface{}, bool, error) {
+	if !config.IsKnown() || config.IsNull() {
+		return nil, false, nil
+	}
+
+	name := config.GetAttr(sfName)
+	password := config.GetAttr(sfCertificatePassword)
+	if !name.IsKnown() || !password.IsKnown() {
+		return nil, false, nil
+	}
+	if name.IsNull() || !certificates.IsRecognised(name.AsString()) {
+		return []interface{}{}, true, nil
+	}
+
+	content, ok, err := readConfiguredSecureFileContent(config)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	passwordValue := ""
+	if !password.IsNull() {
+		passwordValue = password.AsString()
+	}
+
+	parsedCertificates, err := certificates.Parse(name.AsString(), content, passwordValue)
+	if err != nil {
+		return nil, false, fmt.Errorf("error parsing certificates in secure file (%s): %v", name.AsString(), err)
+	}
+
+	return flattenCertificates(parsedCertificates), true, nil
+}
+
+// readConfiguredSecureFileContent returns the content configured in the raw configuration, or false when it cannot be
+// read yet, such as when it depends on other resources or its source file does not exist until applying. Any other
+// error reading the source file is returned.
+func readConfiguredSecureFileContent(config cty.Value) ([]byte, bool, error) {
+	source := config.GetAttr(sfSource)
+	content := config.GetAttr(sfContent)
+	contentBase64 := config.GetAttr(sfContentBase64)
+	if !source.IsKnown() || !content.IsKnown() || !contentBase64.IsKnown() ||
+		!config.GetAttr(sfContentWo).IsKnown() || !config.GetAttr(sfContentBase64Wo).IsKnown() {
+		return nil, false, nil
+	}
+
+	if data, ok, err := writeOnlySecureFileContent(config); err != nil || ok {
+		return data, ok, err
+	}
+
+	switch {
+	case !source.IsNull() && source.AsString() != "":
+		data, err := os.ReadFile(source.AsString())
+		if errors.Is(err, fs.ErrNotExist) {
+			// The source file may be created by another resource while applying
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	case !content.IsNull() && content.AsString() != "":
+		return []byte(content.AsString()), true, nil
+	case !contentBase64.IsNull():
+		data, err := base64.StdEncoding.DecodeString(contentBase64.AsString())
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	default:
+		return []byte{}, true, nil
+	}
+}
+
+// parseSecureFileCertificates parses the certificates contained in the content being applied, which may differ from
+// those planned when the content was not yet known while planning. The content of an adopted secure file is
+// downloaded, as the configured content was not uploaded in its place.
+func parseSecureFileCertificates(
+	clients *client.Clients, ctx context.Context, d *schema.ResourceData, projectId *string, secureFileId *uuid.UUID,
+) ([]interface{}, error) {
+	name := d.Get(sfName).(string)
+	if !d.Get(sfParseCertificates).(bool) || !certificates.IsRecognised(name) {
+		return []interface{}{}, nil
+	}
+
+	var content io.ReadCloser
+	var err error
+	if d.Get(sfContent).(string) == secureFileContentAdopted {
+		content, err = downloadSecureFileContent(clients, ctx, projectId, secureFileId)
+	} else {
+		content, err = openSecureFileContent(d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedCertificates, err := certificates.Parse(name, data, d.Get(sfCertificatePassword).(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return flattenCertificates(parsedCertificates), nil
+}
+
+func flattenCertificates(parsedCertificates []certificates.Certificate) []interface{} {
+	flattened := make([]interface{}, 0, len(parsedCertificates))
+	for _, certificate := range parsedCertificates {
+		flattened = append(
+			flattened, map[string]interface{}{
+				certType:       certificate.Type,
+				certSubject:    certificate.Subject,
+				certIssuer:     certificate.Issuer,
+				certThumbprint: certificate.Thumbprint,
+				certNotBefore:  certificate.NotBefore.Format(time.RFC3339),
+				certNotAfter:   certificate.NotAfter.Format(time.RFC3339),
+				certTeamId:     certificate.TeamId,
+			},
+		)
+	}
+	return flattened
+}
+
+// certificateExpiryWarnings warns about each certificate which has expired or expires within the given number of days.
+func certificateExpiryWarnings(
+	secureFileName string, flattenedCertificates []interface{}, warningDays int, now time.Time,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, flattened := range flattenedCertificates {
+		certificate := flattened.(map[string]interface{})
+
+		notAfter, err := time.Parse(time.RFC3339, certificate[certNotAfter].(string))
+		if err != nil {
+			continue
+		}
+
+		summary, expiry := "", ""
+		if !now.Before(notAfter) {
+			summary, expiry = "Secure file contains an expired "+certificateTypeDescription(certificate), "expired"
+		} else if notAfter.Before(now.AddDate(0, 0, warningDays)) {
+			summary, expiry = "Secure file contains an expiring "+certificateTypeDescription(certificate), "expires"
+		} else {
+			continue
+		}
+
+		diags = append(
+			diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  summary,
+				Detail: fmt.Sprintf(
+					"The %s %q with thumbprint %s in secure file %q %s at %s.",
+					certificateTypeDescription(certificate), certificate[certSubject], certificate[certThumbprint],
+					secureFileName, expiry, notAfter.Format(time.RFC3339),
+				),
+			},
+		)
+	}
+	return diags
+}
+
+func certificateTypeDescription(certificate map[string]interface{}) string {
+	if certificate[certType] == certificates.TypeProvisioningProfile {
+		return "provisioning profile"
+	}
+	return "certificate"
+}
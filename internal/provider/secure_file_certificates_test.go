@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/client/taskagent"
+	"github.com/Xtansia/terraform-provider-azdoext/internal/utils"
+)
+
+func newTestCertificatePem(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestReadConfiguredSecureFileContent(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "hello_world.txt")
+	require.NoError(t, os.WriteFile(source, []byte("Hello World"), 0600))
+
+	test := func(
+		content cty.Value, contentBase64 cty.Value, source cty.Value, expected string, expectedOk bool,
+		expectErr bool,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			data, ok, err := readConfiguredSecureFileContent(
+				cty.ObjectVal(
+					map[string]cty.Value{
+						sfContent:         content,
+						sfContentBase64:   contentBase64,
+						sfSource:          source,
+						sfContentWo:       cty.NullVal(cty.String),
+						sfContentBase64Wo: cty.NullVal(cty.String),
+					},
+				),
+			)
+			if expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, expectedOk, ok)
+			if expectedOk {
+				require.Equal(t, expected, string(data))
+			}
+		}
+	}
+
+	helloWorldBase64 := base64.StdEncoding.EncodeToString([]byte("Hello World"))
+
+	t.Run(
+		"content",
+		test(
+			cty.StringVal("Hello World"), cty.NullVal(cty.String), cty.NullVal(cty.String), "Hello World", true,
+			false,
+		),
+	)
+	t.Run(
+		"content_base64",
+		test(
+			cty.NullVal(cty.String), cty.StringVal(helloWorldBase64), cty.NullVal(cty.String), "Hello World", true,
+			false,
+		),
+	)
+	t.Run(
+		"source",
+		test(cty.NullVal(cty.String), cty.NullVal(cty.String), cty.StringVal(source), "Hello World", true, false),
+	)
+	t.Run(
+		"empty",
+		test(cty.NullVal(cty.String), cty.NullVal(cty.String), cty.NullVal(cty.String), "", true, false),
+	)
+	t.Run(
+		"unknown",
+		test(cty.UnknownVal(cty.String), cty.NullVal(cty.String), cty.NullVal(cty.String), "", false, false),
+	)
+	t.Run(
+		"source_missing",
+		test(
+			cty.NullVal(cty.String), cty.NullVal(cty.String), cty.StringVal(filepath.Join(t.TempDir(), "missing")),
+			"", false, false,
+		),
+	)
+	t.Run(
+		"source_directory",
+		test(cty.NullVal(cty.String), cty.NullVal(cty.String), cty.StringVal(t.TempDir()), "", false, true),
+	)
+}
+
+func TestParseSecureFileCertificates(t *testing.T) {
+	notAfter := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	certificatePem := newTestCertificatePem(t, notAfter)
+	secureFileId := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	clients := &client.Clients{
+		TaskAgentClient: &fakeTaskAgentClient{
+			content:     certificatePem,
+			secureFiles: []taskagent.SecureFile{{Id: &secureFileId, Name: utils.NewString("ca.pem")}},
+		},
+	}
+
+	test := func(raw map[string]interface{}, expectedCount int) func(*testing.T) {
+		return func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceSecureFile().Schema, raw)
+
+			parsedCertificates, err := parseSecureFileCertificates(
+				clients, context.Background(), d, utils.NewString("project"), &secureFileId,
+			)
+			require.NoError(t, err)
+			require.Len(t, parsedCertificates, expectedCount)
+
+			for _, parsedCertificate := range parsedCertificates {
+				require.Equal(t, "CN=test", parsedCertificate.(map[string]interface{})[certSubject])
+				require.Equal(t, "2030-01-02T03:04:05Z", parsedCertificate.(map[string]interface{})[certNotAfter])
+			}
+		}
+	}
+
+	t.Run(
+		"parsed", test(
+			map[string]interface{}{sfName: "ca.pem", sfContent: certificatePem, sfParseCertificates: true}, 1,
+		),
+	)
+	t.Run(
+		"adopted", test(
+			map[string]interface{}{
+				sfName: "ca.pem", sfContent: secureFileContentAdopted, sfParseCertificates: true,
+			}, 1,
+		),
+	)
+	t.Run(
+		"disabled", test(
+			map[string]interface{}{sfName: "ca.pem", sfContent: certificatePem, sfParseCertificates: false}, 0,
+		),
+	)
+	t.Run(
+		"unrecognised", test(
+			map[string]interface{}{sfName: "ca.txt", sfContent: certificatePem, sfParseCertificates: true}, 0,
+		),
+	)
+}
+
+func TestCertificateExpiryWarnings(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	test := func(notAfter string, warningDays int, expectedSummary string) func(*testing.T) {
+		return func(t *testing.T) {
+			diags := certificateExpiryWarnings(
+				"signing.p12", []interface{}{
+					map[string]interface{}{
+						certType:       "certificate",
+						certSubject:    "CN=test",
+						certThumbprint: "ABCDEF",
+						certNotAfter:   notAfter,
+					},
+				}, warningDays, now,
+			)
+
+			if expectedSummary == "" {
+				require.Empty(t, diags)
+				return
+			}
+			require.Len(t, diags, 1)
+			require.Equal(t, diag.Warning, diags[0].Severity)
+			require.Equal(t, expectedSummary, diags[0].Summary)
+		}
+	}
+
+	t.Run("valid", test("2022-08-01T00:00:00Z", 30, ""))
+	t.Run("expiring", test("2022-06-15T00:00:00Z", 30, "Secure file contains an expiring certificate"))
+	t.Run("expiring_outside_window", test("2022-06-15T00:00:00Z", 7, ""))
+	t.Run("expired", test("2022-05-01T00:00:00Z", 30, "Secure file contains an expired certificate"))
+	t.Run("expired_without_window", test("2022-05-01T00:00:00Z", 0, "Secure file contains an expired certificate"))
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// secureFileContentHashRegexp matches the hashes secureFileContentHash stores in state.
+var secureFileContentHashRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// resourceSecureFileV0 is the schema of azdoext_secure_file before it declared a schema version. It covers every
+// release up to and including the one which introduced schema versioning, as attributes were only ever added.
+func resourceSecureFileV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			sfProjectId:     {Type: schema.TypeString, Required: true},
+			sfName:          {Type: schema.TypeString, Required: true},
+			sfContent:       {Type: schema.TypeString, Optional: true},
+			sfContentBase64: {Type: schema.TypeString, Optional: true},
+			sfSource:        {Type: schema.TypeString, Optional: true},
+			sfAllowAccess:   {Type: schema.TypeBool, Optional: true},
+			sfPipelineIds: {
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+				Optional: true,
+				Computed: true,
+			},
+			sfProperties: {
+				Type:     schema.TypeMap,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			sfDetectDrift:                  {Type: schema.TypeBool, Optional: true},
+			sfParseCertificates:            {Type: schema.TypeBool, Optional: true},
+			sfCertificatePassword:          {Type: schema.TypeString, Optional: true, Sensitive: true},
+			sfCertificateExpiryWarningDays: {Type: schema.TypeInt, Optional: true},
+			sfCertificate: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     secureFileCertificateResource(),
+			},
+			sfContentWoVersion:     {Type: schema.TypeInt, Optional: true},
+			sfCreatedBy:            {Type: schema.TypeString, Computed: true},
+			sfCreatedByDescriptor:  {Type: schema.TypeString, Computed: true},
+			sfCreatedOn:            {Type: schema.TypeString, Computed: true},
+			sfModifiedBy:           {Type: schema.TypeString, Computed: true},
+			sfModifiedByDescriptor: {Type: schema.TypeString, Computed: true},
+			sfModifiedOn:           {Type: schema.TypeString, Computed: true},
+			sfOnConflict:           {Type: schema.TypeString, Optional: true},
+			"id":                   {Type: schema.TypeString, Computed: true},
+		},
+	}
+}
+
+// resourceSecureFileStateUpgradeV0 converts the content stored in state by releases before v0.3.0, which stored the
+// content in plain text, to the hash stored by later releases, so that no content is ever left in state. Later releases
+// store a hash in both content attributes, whereas earlier releases left the one which was not configured empty or
+// omitted it, which is also how plain-text content that happens to look like a hash is told apart from one. Content
+// which records that the secure file was adopted is left as-is. The certificate password, which was also stored in
+// plain text, is likewise replaced by its hash.
+//
+// Attributes added since, which have defaults, are set to those defaults, so that upgrading does not plan a change to
+// them.
+func resourceSecureFileStateUpgradeV0(
+	_ context.Context, rawState map[string]interface{}, _ interface{},
+) (map[string]interface{}, error) {
+	if !isSecureFileContentHashed(rawState) {
+		for _, key := range []string{sfContent, sfContentBase64} {
+			content, _ := rawState[key].(string)
+			if content != secureFileContentAdopted {
+				rawState[key] = secureFileContentHash(content)
+			}
+		}
+	}
+
+	if password, _ := rawState[sfCertificatePassword].(string); password != "" {
+		rawState[sfCertificatePassword] = secureFileCertificatePasswordHash(password)
+	}
+
+	defaults := map[string]interface{}{
+		sfAllowAccess:                  false,
+		sfDetectDrift:                  false,
+		sfParseCertificates:            false,
+		sfCertificateExpiryWarningDays: defaultCertificateExpiryWarningDays,
+		sfOnConflict:                   secureFileOnConflictFail,
+	}
+	for key, value := range defaults {
+		if rawState[key] == nil {
+			rawState[key] = value
+		}
+	}
+
+	return rawState, nil
+}
+
+// isSecureFileContentHashed reports whether both content attributes in the raw state hold hashes, or record that the
+// secure file was adopted, as stored since v0.3.0.
+func isSecureFileContentHashed(rawState map[string]interface{}) bool {
+	for _, key := range []string{sfContent, sfContentBase64} {
+		content, _ := rawState[key].(string)
+		if content != secureFileContentAdopted && !secureFileContentHashRegexp.MatchString(content) {
+			return false
+		}
+	}
+	return true
+}
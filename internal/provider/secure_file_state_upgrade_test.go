@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceSecureFileStateUpgradeV0(t *testing.T) {
+	test := func(
+		rawState map[string]interface{}, expected map[string]interface{}, plainText ...string,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			upgraded, err := resourceSecureFileStateUpgradeV0(context.Background(), rawState, nil)
+			require.NoError(t, err)
+			require.Equal(t, expected, upgraded)
+			for _, content := range plainText {
+				require.NotContains(t, fmt.Sprint(upgraded), content)
+			}
+		}
+	}
+
+	withDefaults := func(state map[string]interface{}) map[string]interface{} {
+		defaults := map[string]interface{}{
+			sfAllowAccess:                  false,
+			sfDetectDrift:                  false,
+			sfParseCertificates:            false,
+			sfCertificateExpiryWarningDays: defaultCertificateExpiryWarningDays,
+			sfOnConflict:                   secureFileOnConflictFail,
+		}
+		for key, value := range state {
+			defaults[key] = value
+		}
+		return defaults
+	}
+
+	helloWorldHash := secureFileContentHash("Hello World")
+	emptyHash := secureFileContentHash("")
+	// Plain-text content, such as an API token, which looks like a hash when taken on its own
+	hashLikeContent := strings.Repeat("0123456789abcdef", 4)
+
+	t.Run(
+		"v0.1.0_content", test(
+			map[string]interface{}{
+				"id":            "00000000-0000-0000-0000-000000000001",
+				sfProjectId:     "00000000-0000-0000-0000-000000000002",
+				sfName:          "hello_world.txt",
+				sfContent:       "Hello World",
+				sfContentBase64: nil,
+			},
+			withDefaults(
+				map[string]interface{}{
+					"id":            "00000000-0000-0000-0000-000000000001",
+					sfProjectId:     "00000000-0000-0000-0000-000000000002",
+					sfName:          "hello_world.txt",
+					sfContent:       helloWorldHash,
+					sfContentBase64: emptyHash,
+				},
+			),
+			"Hello World",
+		),
+	)
+	t.Run(
+		"v0.1.0_content_like_hash", test(
+			map[string]interface{}{
+				"id":            "00000000-0000-0000-0000-000000000001",
+				sfProjectId:     "00000000-0000-0000-0000-000000000002",
+				sfName:          "token.txt",
+				sfContent:       hashLikeContent,
+				sfContentBase64: "",
+			},
+			withDefaults(
+				map[string]interface{}{
+					"id":            "00000000-0000-0000-0000-000000000001",
+					sfProjectId:     "00000000-0000-0000-0000-000000000002",
+					sfName:          "token.txt",
+					sfContent:       secureFileContentHash(hashLikeContent),
+					sfContentBase64: emptyHash,
+				},
+			),
+			hashLikeContent,
+		),
+	)
+	t.Run(
+		"v0.2.0_content_base64", test(
+			map[string]interface{}{
+				"id":            "00000000-0000-0000-0000-000000000001",
+				sfProjectId:     "00000000-0000-0000-0000-000000000002",
+				sfName:          "hello_world.txt",
+				sfContent:       nil,
+				sfContentBase64: "SGVsbG8gV29ybGQ=",
+				sfAllowAccess:   true,
+			},
+			withDefaults(
+				map[string]interface{}{
+					"id":            "00000000-0000-0000-0000-000000000001",
+					sfProjectId:     "00000000-0000-0000-0000-000000000002",
+					sfName:          "hello_world.txt",
+					sfContent:       emptyHash,
+					sfContentBase64: helloWorldHash,
+					sfAllowAccess:   true,
+				},
+			),
+			"SGVsbG8gV29ybGQ=", "Hello World",
+		),
+	)
+	t.Run(
+		"v0.3.0_hashed", test(
+			map[string]interface{}{
+				"id":            "00000000-0000-0000-0000-000000000001",
+				sfProjectId:     "00000000-0000-0000-0000-000000000002",
+				sfName:          "hello_world.txt",
+				sfContent:       helloWorldHash,
+				sfContentBase64: emptyHash,
+				sfAllowAccess:   false,
+				sfProperties:    map[string]interface{}{"foo": "bar"},
+			},
+			withDefaults(
+				map[string]interface{}{
+					"id":            "00000000-0000-0000-0000-000000000001",
+					sfProjectId:     "00000000-0000-0000-0000-000000000002",
+					sfName:          "hello_world.txt",
+					sfContent:       helloWorldHash,
+					sfContentBase64: emptyHash,
+					sfProperties:    map[string]interface{}{"foo": "bar"},
+				},
+			),
+		),
+	)
+	t.Run(
+		"imported", test(
+			map[string]interface{}{
+				"id":            "00000000-0000-0000-0000-000000000001",
+				sfProjectId:     "00000000-0000-0000-0000-000000000002",
+				sfName:          "hello_world.txt",
+				sfContent:       secureFileContentAdopted,
+				sfContentBase64: secureFileContentAdopted,
+				sfSource:        secureFileContentAdopted,
+			},
+			withDefaults(
+				map[string]interface{}{
+					"id":            "00000000-0000-0000-0000-000000000001",
+					sfProjectId:     "00000000-0000-0000-0000-000000000002",
+					sfName:          "hello_world.txt",
+					sfContent:       secureFileContentAdopted,
+					sfContentBase64: secureFileContentAdopted,
+					sfSource:        secureFileContentAdopted,
+				},
+			),
+		),
+	)
+
+	current := func(certificatePassword string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":                           "00000000-0000-0000-0000-000000000001",
+			sfProjectId:                    "00000000-0000-0000-0000-000000000002",
+			sfName:                         "signing.p12",
+			sfContent:                      emptyHash,
+			sfContentBase64:                emptyHash,
+			sfSource:                       helloWorldHash,
+			sfAllowAccess:                  true,
+			sfDetectDrift:                  true,
+			sfParseCertificates:            true,
+			sfCertificatePassword:          certificatePassword,
+			sfCertificateExpiryWarningDays: 7,
+			sfOnConflict:                   secureFileOnConflictAdopt,
+			sfContentWoVersion:             nil,
+		}
+	}
+	t.Run("current", test(current(""), current("")))
+	t.Run(
+		"certificate_password",
+		test(current("secret"), current(secureFileCertificatePasswordHash("secret")), "secret"),
+	)
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+)
+
+// NewProtoV5ProviderServer returns a factory for the provider server, which muxes the SDKv2 provider with the framework
+// provider so that each resource can be implemented with either.
+func NewProtoV5ProviderServer(ctx context.Context, version string) (func() tfprotov5.ProviderServer, error) {
+	// Both providers are configured by the mux server, so they share the clients to authenticate only once
+	clients := &sharedClients{}
+	providers := []func() tfprotov5.ProviderServer{
+		newProvider(version, clients).GRPCProvider,
+		providerserver.NewProtocol5(&frameworkProvider{version: version, clients: clients}),
+	}
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
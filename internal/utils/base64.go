@@ -0,0 +1,11 @@
+package utils
+
+import "strings"
+
+// Base64DecodedLen returns the exact number of bytes the standard base64 encoded string decodes to, assuming it is
+// valid. Unlike base64.StdEncoding.DecodedLen it accounts for padding and ignored newline characters.
+func Base64DecodedLen(s string) int64 {
+	s = strings.NewReplacer("\r", "", "\n", "").Replace(s)
+	padding := len(s) - len(strings.TrimRight(s, "="))
+	return int64(len(s)/4*3 - padding)
+}
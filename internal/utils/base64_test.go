@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase64DecodedLen(t *testing.T) {
+	test := func(input string) func(*testing.T) {
+		return func(t *testing.T) {
+			decoded, err := base64.StdEncoding.DecodeString(input)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(decoded)), Base64DecodedLen(input))
+		}
+	}
+
+	t.Run("empty", test(""))
+	t.Run("no_padding", test("SGVsbG8g"))
+	t.Run("one_padding", test("SGVsbG8="))
+	t.Run("two_padding", test("SGVsbA=="))
+	t.Run("newlines", test("SGVs\r\nbG8g\nV29y\nbGQ=\n"))
+}
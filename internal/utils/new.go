@@ -7,3 +7,7 @@ func NewInt(i int) *int {
 func NewString(s string) *string {
 	return &s
 }
+
+func NewBool(b bool) *bool {
+	return &b
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 
 	"github.com/Xtansia/terraform-provider-azdoext/internal/provider"
 )
@@ -22,12 +24,19 @@ func main() {
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := &plugin.ServeOpts{ProviderFunc: provider.New(version)}
+	serverFactory, err := provider.NewProtoV5ProviderServer(context.Background(), version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var serveOpts []tf5server.ServeOpt
 
 	if debugMode {
-		opts.Debug = true
-		opts.ProviderAddr = "registry.terraform.io/Xtansia/azdoext"
+		serveOpts = append(serveOpts, tf5server.WithManagedDebug())
 	}
 
-	plugin.Serve(opts)
+	err = tf5server.Serve("registry.terraform.io/Xtansia/azdoext", serverFactory, serveOpts...)
+	if err != nil {
+		log.Fatal(err)
+	}
 }